@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+// loadConfig reads the --config file, when given, and applies the command
+// line flags and environment variables on top of it. The flag defaults are
+// applied before reading the file, so values in the file, zero values
+// included, are only overridden by flags that are set.
+func loadConfig(c *cli.Context) (*config.Config, error) {
+	cfg := &config.Config{}
+	applyFlags(c, cfg, func(string) bool { return true })
+	if path := c.String("config"); path != "" {
+		if err := config.LoadInto(path, cfg); err != nil {
+			return nil, err
+		}
+		applyFlags(c, cfg, c.IsSet)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyFlags sets the configuration values of the flags for which apply
+// returns true.
+func applyFlags(c *cli.Context, cfg *config.Config, apply func(flag string) bool) {
+	if apply("port") {
+		cfg.ListenAddress = net.JoinHostPort("", c.String("port"))
+	}
+	if apply("timeout") {
+		cfg.Timeout = c.Duration("timeout")
+	}
+	if apply("poll-interval") {
+		cfg.PollInterval = c.Duration("poll-interval")
+	}
+	if apply("collect-structured") {
+		cfg.Collect.Structured = c.Bool("collect-structured")
+	}
+	if apply("collect-validator-list") {
+		cfg.Collect.ValidatorList = c.Bool("collect-validator-list")
+	}
+	if apply("collect-validator-set") {
+		cfg.Collect.ValidatorSet = c.Bool("collect-validator-set")
+	}
+	if apply("collect-offers-complaints") {
+		cfg.Collect.OffersComplaints = c.Bool("collect-offers-complaints")
+	}
+	if apply("collect-election-entries") {
+		cfg.Collect.ElectionEntries = c.Bool("collect-election-entries")
+	}
+	if apply("collect-pools") {
+		cfg.Collect.Pools = c.Bool("collect-pools")
+	}
+	if apply("validator-set-limit") {
+		cfg.Collect.ValidatorSetLimit = c.Int("validator-set-limit")
+	}
+	if apply("past-stakes-limit") {
+		cfg.Collect.PastStakesLimit = c.Int("past-stakes-limit")
+	}
+	if apply("accounts-interval") {
+		cfg.Collect.AccountsInterval = c.Duration("accounts-interval")
+	}
+
+	if apply("legacy-database-size-gb") {
+		cfg.Metrics.LegacyDatabaseSizeGB = c.Bool("legacy-database-size-gb")
+	}
+
+	applyString(c, apply, "state-file", &cfg.StateFile)
+
+	source := &cfg.Source
+	applyString(c, apply, "source", &source.Type)
+	applyString(c, apply, "source-command", &source.Command)
+	applyString(c, apply, "source-file", &source.File)
+	applyString(c, apply, "mytoncore-db", &source.MytoncoreDB)
+	applyString(c, apply, "docker-container", &source.DockerContainer)
+	applyString(c, apply, "sudo-user", &source.SudoUser)
+	applyString(c, apply, "ssh-host", &source.SSHHost)
+	applyString(c, apply, "mytonctrl-path", &source.MytonctrlPath)
+	applyString(c, apply, "docker-path", &source.DockerPath)
+	applyString(c, apply, "sudo-path", &source.SudoPath)
+	applyString(c, apply, "nsenter-path", &source.NsenterPath)
+	applyString(c, apply, "ssh-path", &source.SSHPath)
+	if apply("nsenter-pid") {
+		source.NsenterPID = c.Int("nsenter-pid")
+	}
+}
+
+// applyString sets value from the flag when apply returns true for it.
+func applyString(c *cli.Context, apply func(flag string) bool, flag string, value *string) {
+	if apply(flag) {
+		*value = c.String(flag)
+	}
+}
+
+// parserOptions returns the parser options enabled by the configuration.
+func parserOptions(cfg *config.Config) collector.ParserOptions {
+	accounts := make([]collector.WatchedAccount, 0, len(cfg.Collect.Accounts))
+	for _, account := range cfg.Collect.Accounts {
+		accounts = append(accounts, collector.WatchedAccount{Address: account.Address, Alias: account.Alias})
+	}
+
+	return collector.ParserOptions{
+		Structured:        cfg.Collect.Structured,
+		ValidatorList:     cfg.Collect.ValidatorList,
+		ValidatorSet:      cfg.Collect.ValidatorSet,
+		ValidatorSetLimit: cfg.Collect.ValidatorSetLimit,
+		PastStakesLimit:   cfg.Collect.PastStakesLimit,
+		OffersComplaints:  cfg.Collect.OffersComplaints,
+		ElectionEntries:   cfg.Collect.ElectionEntries,
+		Accounts:          accounts,
+		AccountsInterval:  cfg.Collect.AccountsInterval,
+		Pools:             cfg.Collect.Pools,
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name              string
+		file              string
+		args              []string
+		wantListenAddress string
+		wantTimeout       time.Duration
+		wantSetLimit      int
+		wantInterval      time.Duration
+		wantSource        string
+	}{
+		{
+			name:              "flag defaults",
+			wantListenAddress: ":9100",
+			wantTimeout:       30 * time.Second,
+			wantSetLimit:      500,
+			wantInterval:      5 * time.Minute,
+			wantSource:        "mytonctrl",
+		},
+		{
+			name:              "values missing from the file",
+			file:              "listen_address: 127.0.0.1:9200\n",
+			wantListenAddress: "127.0.0.1:9200",
+			wantTimeout:       30 * time.Second,
+			wantSetLimit:      500,
+			wantInterval:      5 * time.Minute,
+			wantSource:        "mytonctrl",
+		},
+		{
+			name:              "zero values in the file",
+			file:              "timeout: 0s\ncollect:\n  validator_set_limit: 0\n",
+			wantListenAddress: ":9100",
+			wantSetLimit:      0,
+			wantInterval:      5 * time.Minute,
+			wantSource:        "mytonctrl",
+		},
+		{
+			name:              "set flags override the file",
+			file:              "timeout: 0s\ncollect:\n  validator_set_limit: 0\nsource:\n  type: file\n  file: status.txt\n",
+			args:              []string{"--timeout", "5s", "--validator-set-limit", "100", "--port", "9300"},
+			wantListenAddress: ":9300",
+			wantTimeout:       5 * time.Second,
+			wantSetLimit:      100,
+			wantInterval:      5 * time.Minute,
+			wantSource:        "file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"exporter"}
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				args = append(args, "--config", path)
+			}
+			args = append(args, tt.args...)
+
+			app := &cli.App{
+				Flags: flags(),
+				Action: func(c *cli.Context) error {
+					cfg, err := loadConfig(c)
+					if err != nil {
+						return err
+					}
+
+					if cfg.ListenAddress != tt.wantListenAddress {
+						t.Errorf("ListenAddress = %q, want %q", cfg.ListenAddress, tt.wantListenAddress)
+					}
+					if cfg.Timeout != tt.wantTimeout {
+						t.Errorf("Timeout = %s, want %s", cfg.Timeout, tt.wantTimeout)
+					}
+					if cfg.Collect.ValidatorSetLimit != tt.wantSetLimit {
+						t.Errorf("Collect.ValidatorSetLimit = %d, want %d", cfg.Collect.ValidatorSetLimit, tt.wantSetLimit)
+					}
+					if cfg.Collect.AccountsInterval != tt.wantInterval {
+						t.Errorf("Collect.AccountsInterval = %s, want %s", cfg.Collect.AccountsInterval, tt.wantInterval)
+					}
+					if cfg.Source.Type != tt.wantSource {
+						t.Errorf("Source.Type = %q, want %q", cfg.Source.Type, tt.wantSource)
+					}
+					return nil
+				},
+			}
+			if err := app.Run(args); err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+		})
+	}
+}
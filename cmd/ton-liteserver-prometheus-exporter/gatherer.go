@@ -0,0 +1,23 @@
+package main
+
+import (
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+// filterGatherer drops the metric families rejected by filter from the gathered metrics.
+func filterGatherer(gatherer prometheus.Gatherer, filter *config.MetricFilter) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		filtered := families[:0]
+		for _, family := range families {
+			if filter.Match(family.GetName()) {
+				filtered = append(filtered, family)
+			}
+		}
+		return filtered, err
+	})
+}
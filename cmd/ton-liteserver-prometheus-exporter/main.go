@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,11 +11,13 @@ import (
 	"os/signal"
 	"runtime/debug"
 	"syscall"
+	"time"
 
 	"github.com/oklog/run"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
 
 	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
 )
@@ -47,34 +50,62 @@ func main() {
 		Name:    "lightserver-prometheus-exporter",
 		Usage:   "Prometheus exporter for TON LightServer",
 		Version: version,
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:    "port",
-				Usage:   "Port to listen on for Prometheus metrics",
-				EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_PORT"},
-				Value:   "9100",
-			},
-		},
+		Flags:   flags(),
 		Action: func(c *cli.Context) error {
-			collector := collector.NewMytonCollector(collector.NewParser())
-			if err := prometheus.Register(collector); err != nil {
+			cfg, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+
+			source, err := newSource(cfg.Source)
+			if err != nil {
+				return err
+			}
+
+			filter, err := cfg.Metrics.Filter()
+			if err != nil {
+				return err
+			}
+
+			collector := collector.NewMytonCollector(collector.NewParser(source, cfg.Timeout, parserOptions(cfg)), cfg.PollInterval)
+			if cfg.StateFile != "" {
+				if err := collector.LoadState(cfg.StateFile); err != nil {
+					return err
+				}
+			}
+			registerer := prometheus.WrapRegistererWith(cfg.ConstLabels, prometheus.DefaultRegisterer)
+			if err := registerer.Register(collector); err != nil {
 				return fmt.Errorf("error registering collector: %w", err)
 			}
 
 			cancelInterrupt := make(chan struct{})
 			var g run.Group
 			{
-				prometheusListener, err := net.Listen("tcp", net.JoinHostPort("", c.String("port")))
+				prometheusListener, err := net.Listen("tcp", cfg.ListenAddress)
 				if err != nil {
 					return fmt.Errorf("failed to listen: %w", err)
 				}
 				g.Add(func() error {
 					log.Printf("Starting server on %s", prometheusListener.Addr())
-					return http.Serve(prometheusListener, promhttp.Handler())
+					mux := http.NewServeMux()
+					mux.Handle("/probe", probeHandler(cfg, filter))
+					mux.Handle("/", promhttp.InstrumentMetricHandler(
+						prometheus.DefaultRegisterer,
+						metricsHandler(filterGatherer(prometheus.DefaultGatherer, filter)),
+					))
+					return http.Serve(prometheusListener, mux)
 				}, func(error) {
 					_ = prometheusListener.Close()
 				})
 			}
+			if cfg.PollInterval > 0 {
+				ctx, cancel := context.WithCancel(c.Context)
+				g.Add(func() error {
+					return collector.Run(ctx)
+				}, func(error) {
+					cancel()
+				})
+			}
 			{
 				// This function just sits and waits for ctrl-C.
 				g.Add(func() error {
@@ -98,8 +129,18 @@ func main() {
 				Name:  "print",
 				Usage: "Print metrics to stdout",
 				Action: func(c *cli.Context) error {
-					parser := collector.NewParser()
-					metrics, err := parser.Parse()
+					cfg, err := loadConfig(c)
+					if err != nil {
+						return err
+					}
+
+					source, err := newSource(cfg.Source)
+					if err != nil {
+						return err
+					}
+
+					parser := collector.NewParser(source, cfg.Timeout, parserOptions(cfg))
+					metrics, err := parser.Parse(c.Context)
 					if err != nil {
 						return fmt.Errorf("error collecting metrics: %w", err)
 					}
@@ -114,6 +155,24 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:  "check-config",
+				Usage: "Validate the configuration and print the effective configuration with flags applied",
+				Action: func(c *cli.Context) error {
+					cfg, err := loadConfig(c)
+					if err != nil {
+						return fmt.Errorf("invalid configuration: %w", err)
+					}
+
+					enc := yaml.NewEncoder(os.Stdout)
+					enc.SetIndent(2)
+					if err := enc.Encode(cfg); err != nil {
+						return fmt.Errorf("error encoding configuration: %w", err)
+					}
+
+					return enc.Close()
+				},
+			},
 		},
 	}
 
@@ -121,3 +180,160 @@ func main() {
 		log.Fatalf("app run error: %s\n", err.Error())
 	}
 }
+
+// flags returns the command line flags of the exporter.
+func flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "Path to the YAML configuration file, flags and environment variables override its values",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_CONFIG"},
+		},
+		&cli.StringFlag{
+			Name:    "port",
+			Usage:   "Port to listen on for Prometheus metrics",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_PORT"},
+			Value:   "9100",
+		},
+		&cli.DurationFlag{
+			Name:    "timeout",
+			Usage:   "Timeout for each mytonctrl command of a scrape, 'status' and every optional command on its own, 0 disables it",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_TIMEOUT"},
+			Value:   30 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    "poll-interval",
+			Usage:   "Poll mytonctrl in the background on this interval and serve cached results, 0 runs it on every scrape",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_POLL_INTERVAL"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-structured",
+			Usage:   "Run 'vl', 'ol' and 'cl' with --json after 'status' to read the validator, offer and complaint counts from JSON when mytonctrl supports it",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_STRUCTURED"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-validator-list",
+			Usage:   "Run 'vl' after 'status' to export the efficiency and created blocks of the local validator",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_VALIDATOR_LIST"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-validator-set",
+			Usage:   "Run 'vl' after 'status' to export metrics for every validator of the current set",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_VALIDATOR_SET"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-offers-complaints",
+			Usage:   "Run 'ol' and 'cl' after 'status' to export the open offers and the complaints",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_OFFERS_COMPLAINTS"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-election-entries",
+			Usage:   "Run 'el' after 'status' to export the stake of the local validator in the current election",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_ELECTION_ENTRIES"},
+		},
+		&cli.BoolFlag{
+			Name:    "collect-pools",
+			Usage:   "Run 'pools_list' and 'get_pool_data' after 'status' to export the nominator pools of the node",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_COLLECT_POOLS"},
+		},
+		&cli.IntFlag{
+			Name:    "validator-set-limit",
+			Usage:   "Maximum number of validators exported by --collect-validator-set, 0 disables the limit",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_VALIDATOR_SET_LIMIT"},
+			Value:   500,
+		},
+		&cli.IntFlag{
+			Name:    "past-stakes-limit",
+			Usage:   "Maximum number of past elections exported by the mytoncore_db source, 0 disables the limit",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_PAST_STAKES_LIMIT"},
+			Value:   10,
+		},
+		&cli.DurationFlag{
+			Name:    "accounts-interval",
+			Usage:   "Minimum time between two 'vas' queries of the same account listed in collect.accounts of the config file",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_ACCOUNTS_INTERVAL"},
+			Value:   5 * time.Minute,
+		},
+		&cli.BoolFlag{
+			Name:    "legacy-database-size-gb",
+			Usage:   "Keep exporting the deprecated local_validator_database_size_gb gauge next to local_validator_database_size_bytes",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_LEGACY_DATABASE_SIZE_GB"},
+		},
+		&cli.StringFlag{
+			Name:    "state-file",
+			Usage:   "File persisting the detected mytoncore and validator restarts across exporter restarts",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_STATE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "source",
+			Usage:   "Where to read the mytonctrl status output from: mytonctrl, docker, sudo, nsenter, ssh, command, file, mytoncore_db or stdin",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SOURCE"},
+			Value:   "mytonctrl",
+		},
+		&cli.StringFlag{
+			Name:    "source-command",
+			Usage:   "Shell command line printing the status output, used by the command source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SOURCE_COMMAND"},
+		},
+		&cli.StringFlag{
+			Name:    "source-file",
+			Usage:   "File containing the status output, used by the file source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SOURCE_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "mytoncore-db",
+			Usage:   "Path to the mytoncore database, e.g. ~/.local/share/mytoncore/mytoncore.db, used by the mytoncore_db source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_MYTONCORE_DB"},
+		},
+		&cli.StringFlag{
+			Name:    "docker-container",
+			Usage:   "Container running mytonctrl, used by the docker source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_DOCKER_CONTAINER"},
+		},
+		&cli.StringFlag{
+			Name:    "sudo-user",
+			Usage:   "User to run mytonctrl as, used by the sudo source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SUDO_USER"},
+		},
+		&cli.IntFlag{
+			Name:    "nsenter-pid",
+			Usage:   "PID of a process whose namespaces mytonctrl runs in, used by the nsenter source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_NSENTER_PID"},
+		},
+		&cli.StringFlag{
+			Name:    "ssh-host",
+			Usage:   "Host to run mytonctrl on, optionally with the user, used by the ssh source",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SSH_HOST"},
+		},
+		&cli.StringFlag{
+			Name:    "mytonctrl-path",
+			Usage:   "Path to the mytonctrl binary",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_MYTONCTRL_PATH"},
+			Value:   collector.DefaultMytonctrlPath,
+		},
+		&cli.StringFlag{
+			Name:    "docker-path",
+			Usage:   "Path to the docker binary",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_DOCKER_PATH"},
+			Value:   "docker",
+		},
+		&cli.StringFlag{
+			Name:    "sudo-path",
+			Usage:   "Path to the sudo binary",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SUDO_PATH"},
+			Value:   "sudo",
+		},
+		&cli.StringFlag{
+			Name:    "nsenter-path",
+			Usage:   "Path to the nsenter binary",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_NSENTER_PATH"},
+			Value:   "nsenter",
+		},
+		&cli.StringFlag{
+			Name:    "ssh-path",
+			Usage:   "Path to the ssh binary",
+			EnvVars: []string{"TON_LITESERVER_PROMETHEUS_EXPORTER_SSH_PATH"},
+			Value:   "ssh",
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
+)
+
+// metricsHandler serves the gathered metrics like promhttp.HandlerFor. When the
+// scraper negotiates OpenMetrics, the state set metrics are written as
+// OpenMetrics StateSets, which the client library cannot encode itself. Those
+// responses are gzipped when the scraper accepts it, as promhttp does.
+func metricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+		if format.FormatType() != expfmt.TypeOpenMetrics {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		families, err := gatherer.Gather()
+		if err != nil {
+			log.Printf("Error gathering metrics: %v", err)
+			http.Error(w, "An error has occurred while gathering metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := writeOpenMetrics(&buf, families); err != nil {
+			log.Printf("Error encoding metrics: %v", err)
+			http.Error(w, "An error has occurred while encoding metrics:\n\n"+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", string(format))
+		if !acceptsGzip(r) {
+			_, _ = w.Write(buf.Bytes())
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write(buf.Bytes())
+		if err := gz.Close(); err != nil {
+			log.Printf("Error compressing metrics: %v", err)
+		}
+	})
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if strings.TrimSpace(name) != "gzip" {
+				continue
+			}
+			weight, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !found {
+				return true
+			}
+			q, err := strconv.ParseFloat(weight, 64)
+			return err == nil && q > 0
+		}
+	}
+	return false
+}
+
+// writeOpenMetrics writes the metric families in the OpenMetrics text format.
+func writeOpenMetrics(w io.Writer, families []*dto.MetricFamily) error {
+	for _, family := range families {
+		label, ok := collector.StateSetLabel(family.GetName())
+		if !ok {
+			if _, err := expfmt.MetricFamilyToOpenMetrics(w, family); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := writeStateSet(w, family, label); err != nil {
+			return err
+		}
+	}
+
+	_, err := expfmt.FinalizeOpenMetrics(w)
+	return err
+}
+
+// writeStateSet writes a gauge family holding its states in label as a
+// StateSet, whose states are held in a label named after the family.
+func writeStateSet(w io.Writer, family *dto.MetricFamily, label string) error {
+	stateSet, ok := proto.Clone(family).(*dto.MetricFamily)
+	if !ok {
+		return fmt.Errorf("cannot copy metric family %s", family.GetName())
+	}
+	for _, metric := range stateSet.GetMetric() {
+		for _, pair := range metric.GetLabel() {
+			if pair.GetName() == label {
+				pair.Name = proto.String(stateSet.GetName())
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := expfmt.MetricFamilyToOpenMetrics(&buf, stateSet); err != nil {
+		return err
+	}
+
+	gaugeType := []byte("# TYPE " + stateSet.GetName() + " gauge\n")
+	_, err := w.Write(bytes.Replace(buf.Bytes(), gaugeType, []byte("# TYPE "+stateSet.GetName()+" stateset\n"), 1))
+	return err
+}
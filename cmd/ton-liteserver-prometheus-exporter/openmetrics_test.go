@@ -0,0 +1,138 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+// openMetricsGolden is the OpenMetrics exposition of openMetricsStatus, the
+// state sets are written as StateSets labeled by their own name.
+const openMetricsGolden = `# HELP ton_liteserver_exporter_all_validators Total number of validators
+# TYPE ton_liteserver_exporter_all_validators gauge
+ton_liteserver_exporter_all_validators 26.0
+# HELP ton_liteserver_exporter_election_status Election status (open/closed/unknown)
+# TYPE ton_liteserver_exporter_election_status stateset
+ton_liteserver_exporter_election_status{ton_liteserver_exporter_election_status="closed"} 0.0
+ton_liteserver_exporter_election_status{ton_liteserver_exporter_election_status="open"} 1.0
+ton_liteserver_exporter_election_status{ton_liteserver_exporter_election_status="unknown"} 0.0
+# HELP ton_liteserver_exporter_mytoncore_status Status of Mytoncore (working/not working/unknown)
+# TYPE ton_liteserver_exporter_mytoncore_status stateset
+ton_liteserver_exporter_mytoncore_status{ton_liteserver_exporter_mytoncore_status="not working"} 0.0
+ton_liteserver_exporter_mytoncore_status{ton_liteserver_exporter_mytoncore_status="unknown"} 0.0
+ton_liteserver_exporter_mytoncore_status{ton_liteserver_exporter_mytoncore_status="working"} 1.0
+# EOF
+`
+
+const openMetricsStatus = `Number of validators: 23(26)
+Election status: open
+Mytoncore status: working, 14 days
+`
+
+func newOpenMetricsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	filter, err := config.MetricsConfig{Include: []string{
+		"ton_liteserver_exporter_all_validators",
+		"ton_liteserver_exporter_election_status",
+		"ton_liteserver_exporter_mytoncore_status",
+	}}.Filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := prometheus.NewRegistry()
+	source := collector.NewReaderSource(strings.NewReader(openMetricsStatus))
+	registry.MustRegister(collector.NewMytonCollector(collector.NewParser(source, 0, collector.ParserOptions{}), 0))
+
+	server := httptest.NewServer(metricsHandler(filterGatherer(registry, filter)))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMetricsHandler_OpenMetrics(t *testing.T) {
+	server := newOpenMetricsServer(t)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{name: "identity"},
+		{name: "gzip", acceptEncoding: "gzip, deflate", wantEncoding: "gzip"},
+		{name: "gzip refused", acceptEncoding: "gzip;q=0, identity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0")
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			// A custom transport does not decompress the body on its own.
+			resp, err := (&http.Transport{DisableCompression: true}).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/openmetrics-text") {
+				t.Errorf("Content-Type = %q, want OpenMetrics", got)
+			}
+
+			body := io.Reader(resp.Body)
+			if tt.wantEncoding == "gzip" {
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(openMetricsGolden, string(got)); diff != "" {
+				t.Errorf("OpenMetrics output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMetricsHandler_TextFormat(t *testing.T) {
+	server := newOpenMetricsServer(t)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The text format has no state sets, they stay gauges labeled with their state.
+	want := `ton_liteserver_exporter_election_status{status="open"} 1`
+	if !strings.Contains(string(body), "# TYPE ton_liteserver_exporter_election_status gauge") ||
+		!strings.Contains(string(body), want) {
+		t.Errorf("text format output does not contain the election_status gauge:\n%s", body)
+	}
+}
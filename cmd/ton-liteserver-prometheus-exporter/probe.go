@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+// probeHandler serves /probe?target=<name>. Every probe fetches the target's
+// status once and returns only its metrics from a fresh registry. The parser of
+// each target is kept for the life of the process, so state such as the
+// watched accounts cache is shared by its probes.
+func probeHandler(cfg *config.Config, filter *config.MetricFilter) http.Handler {
+	parsers := make(map[string]*collector.Parser, len(cfg.Targets))
+	sourceErrs := make(map[string]error)
+	for _, target := range cfg.Targets {
+		source, err := newSource(target.Source)
+		if err != nil {
+			sourceErrs[target.Name] = err
+			continue
+		}
+
+		timeout := target.Timeout
+		if timeout == 0 {
+			timeout = cfg.Timeout
+		}
+		parsers[target.Name] = collector.NewParser(source, timeout, parserOptions(cfg))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("target")
+		if name == "" {
+			http.Error(w, "target parameter is missing", http.StatusBadRequest)
+			return
+		}
+
+		if _, ok := cfg.Target(name); !ok {
+			http.Error(w, fmt.Sprintf("unknown target %q", name), http.StatusNotFound)
+			return
+		}
+		if err := sourceErrs[name]; err != nil {
+			http.Error(w, fmt.Sprintf("invalid source for target %q: %s", name, err), http.StatusInternalServerError)
+			return
+		}
+
+		probe, err := collector.Probe(r.Context(), parsers[name])
+		if err != nil {
+			log.Printf("Probe of target %q failed: %v", name, err)
+		}
+
+		registry := prometheus.NewRegistry()
+		prometheus.WrapRegistererWith(cfg.ConstLabels, registry).MustRegister(probe)
+		metricsHandler(filterGatherer(registry, filter)).ServeHTTP(w, r)
+	})
+}
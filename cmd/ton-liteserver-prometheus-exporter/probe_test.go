@@ -0,0 +1,182 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+func TestProbeHandler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands are POSIX specific")
+	}
+
+	status := filepath.Join(t.TempDir(), "status.txt")
+	if err := os.WriteFile(status, []byte("Number of validators: 23(26)\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Timeout: 10 * time.Second,
+		Targets: []config.Target{
+			{Name: "node1", Source: config.SourceConfig{Type: config.SourceFile, File: status}},
+			{Name: "failing", Source: config.SourceConfig{Type: config.SourceCommand, Command: "echo failed >&2; exit 3"}},
+			{
+				Name:    "slow",
+				Source:  config.SourceConfig{Type: config.SourceCommand, Command: "sleep 10 | cat"},
+				Timeout: 100 * time.Millisecond,
+			},
+		},
+	}
+	filter, err := cfg.Metrics.Filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(probeHandler(cfg, filter))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "missing target",
+			query:      "",
+			wantStatus: http.StatusBadRequest,
+			want:       []string{"target parameter is missing"},
+		},
+		{
+			name:       "unknown target",
+			query:      "?target=node2",
+			wantStatus: http.StatusNotFound,
+			want:       []string{`unknown target "node2"`},
+		},
+		{
+			name:       "success",
+			query:      "?target=node1",
+			wantStatus: http.StatusOK,
+			want:       []string{"probe_success 1\n", "ton_liteserver_exporter_all_validators 26\n"},
+		},
+		{
+			name:       "failing source",
+			query:      "?target=failing",
+			wantStatus: http.StatusOK,
+			want:       []string{"probe_success 0\n", "probe_duration_seconds "},
+			notWant:    []string{"ton_liteserver_exporter_"},
+		},
+		{
+			name:       "target timeout",
+			query:      "?target=slow",
+			wantStatus: http.StatusOK,
+			want:       []string{"probe_success 0\n"},
+			notWant:    []string{"ton_liteserver_exporter_"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			resp, err := http.Get(server.URL + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("body does not contain %q:\n%s", want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(body), notWant) {
+					t.Errorf("body contains %q:\n%s", notWant, body)
+				}
+			}
+			// The target timeout applies instead of the global one.
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("probe took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestProbeHandler_AccountsInterval(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands are POSIX specific")
+	}
+
+	// The fake mytonctrl prints the status and the account, logging every 'vas' run.
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "vas.log")
+	mytonctrl := filepath.Join(dir, "mytonctrl")
+	script := `#!/bin/sh
+read command
+case "$command" in
+status) echo "Number of validators: 23(26)" ;;
+vas*) echo "$command" >> ` + calls + `
+  printf 'Address  Status  Balance  Version\nEQPool  active  12.5  v1\n' ;;
+esac
+`
+	if err := os.WriteFile(mytonctrl, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Collect: config.CollectConfig{
+			Accounts:         []config.AccountConfig{{Address: "EQPool", Alias: "pool"}},
+			AccountsInterval: time.Hour,
+		},
+		Targets: []config.Target{
+			{Name: "node1", Source: config.SourceConfig{Type: config.SourceMytonctrl, MytonctrlPath: mytonctrl}},
+		},
+	}
+	filter, err := cfg.Metrics.Filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(probeHandler(cfg, filter))
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(server.URL + "?target=node1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `ton_liteserver_exporter_account_balance{address="EQPool",alias="pool"} 12.5`
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body does not contain %q:\n%s", want, body)
+		}
+	}
+
+	// The account is queried once per interval across probes of the target.
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "vas EQPool"); got != 1 {
+		t.Errorf("'vas' ran %d times, want 1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/collector"
+	"github.com/MrEhbr/ton-liteserver-prometheus-exporter/config"
+)
+
+// newSource creates the status output source described by cfg.
+func newSource(cfg config.SourceConfig) (collector.Source, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	mytonctrlPath := withDefault(cfg.MytonctrlPath, collector.DefaultMytonctrlPath)
+	switch cfg.Type {
+	case "", config.SourceMytonctrl:
+		return collector.NewMytonctrlSource(mytonctrlPath), nil
+	case config.SourceDocker:
+		return collector.NewDockerSource(withDefault(cfg.DockerPath, "docker"), cfg.DockerContainer, mytonctrlPath), nil
+	case config.SourceSudo:
+		return collector.NewSudoSource(withDefault(cfg.SudoPath, "sudo"), cfg.SudoUser, mytonctrlPath), nil
+	case config.SourceNsenter:
+		return collector.NewNsenterSource(withDefault(cfg.NsenterPath, "nsenter"), cfg.NsenterPID, mytonctrlPath), nil
+	case config.SourceSSH:
+		return collector.NewSSHSource(withDefault(cfg.SSHPath, "ssh"), cfg.SSHHost, mytonctrlPath), nil
+	case config.SourceCommand:
+		return collector.NewCommandSource(cfg.Command), nil
+	case config.SourceFile:
+		return collector.NewFileSource(cfg.File), nil
+	case config.SourceMytoncoreDB:
+		return collector.NewMytoncoreDBSource(cfg.MytoncoreDB), nil
+	case config.SourceStdin:
+		return collector.NewReaderSource(os.Stdin), nil
+	default:
+		return nil, fmt.Errorf("unknown source type %q", cfg.Type)
+	}
+}
+
+func withDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,23 @@
+package collector
+
+// balanceChange tracks the change of the local validator wallet balance
+// between the scrapes it was read in.
+type balanceChange struct {
+	last Nanotons
+	seen bool
+	// change is the difference between the last two balances, known once the
+	// balance was read twice.
+	change Nanotons
+	known  bool
+}
+
+// observe records the balance of m, when it was parsed.
+func (b *balanceChange) observe(m *LiteServerMetrics) {
+	if !m.Parsed("local_validator_wallet_balance_nanotons") {
+		return
+	}
+	if b.seen {
+		b.change, b.known = m.LocalValidatorWalletBalanceNanotons-b.last, true
+	}
+	b.last, b.seen = m.LocalValidatorWalletBalanceNanotons, true
+}
@@ -2,8 +2,11 @@
 package collector
 
 import (
+	"context"
+	"errors"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -11,8 +14,40 @@ import (
 type MytonCollector struct {
 	metrics       []MetricDef
 	parsingErrors prometheus.Counter
+	failures      *prometheus.CounterVec
+	fieldErrors   *prometheus.CounterVec
 	mutex         sync.Mutex
 	parser        *Parser
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+
+	// Result of the latest scrape, or of the latest poll in polling mode.
+	lastScrapeOK       bool
+	lastScrapeTime     time.Time
+	lastScrapeDuration time.Duration
+	snapshot           *LiteServerMetrics
+	snapshotTime       time.Time
+
+	up                 *prometheus.Desc
+	scrapeDuration     *prometheus.Desc
+	lastSuccessfulTime *prometheus.Desc
+
+	// Background polling mode, enabled when pollInterval is positive.
+	pollInterval    time.Duration
+	snapshotAge     *prometheus.Desc
+	lastSuccessPoll *prometheus.Desc
+
+	// Restarts detected from decreasing uptimes, persisted in stateFile when set.
+	restarts           restartState
+	stateFile          string
+	mytoncoreRestarts  *prometheus.Desc
+	validatorRestarts  *prometheus.Desc
+	mytoncoreStartTime *prometheus.Desc
+	validatorStartTime *prometheus.Desc
+
+	// Change of the wallet balance between the last two snapshots it was read in.
+	balanceChange       balanceChange
+	walletBalanceChange *prometheus.Desc
 }
 
 const (
@@ -20,39 +55,307 @@ const (
 	MetricSubsystem = "exporter"
 )
 
-func NewMytonCollector(parser *Parser) *MytonCollector {
+// Failure causes used as the "cause" label of the scrape failures counter.
+const (
+	failureCauseExec     = "exec"
+	failureCauseExitCode = "exit_code"
+	failureCauseParse    = "parse"
+	failureCauseTimeout  = "timeout"
+	// failureCauseUnavailable is used when the container, user or process to run mytonctrl in is missing.
+	failureCauseUnavailable = "unavailable"
+)
+
+// NewMytonCollector creates a collector that runs the parser on every scrape,
+// or serves the latest snapshot taken by Run when pollInterval is positive.
+func NewMytonCollector(parser *Parser, pollInterval time.Duration) *MytonCollector {
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "scrape_failures_total"),
+		Help: "Total number of failed mytonctrl scrapes by cause",
+	}, []string{"cause"})
+	for _, cause := range []string{
+		failureCauseExec, failureCauseExitCode, failureCauseParse, failureCauseTimeout, failureCauseUnavailable,
+	} {
+		failures.WithLabelValues(cause)
+	}
+
 	return &MytonCollector{
 		metrics: Metrics,
 		parsingErrors: prometheus.NewCounter(prometheus.CounterOpts{
 			Name: prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "parsing_errors_total"),
 			Help: "Total number of parsing errors encountered during metric collection",
 		}),
+		failures: failures,
+		fieldErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "field_parse_errors_total"),
+			Help: "Total number of fields present in the mytonctrl output whose value could not be parsed",
+		}, []string{"field"}),
 		parser: parser,
+		now:    time.Now,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "up"),
+			"Whether the last mytonctrl scrape was successful",
+			nil, nil,
+		),
+		scrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "scrape_duration_seconds"),
+			"Duration of the last mytonctrl scrape in seconds",
+			nil, nil,
+		),
+		lastSuccessfulTime: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "last_successful_scrape_timestamp_seconds"),
+			"Unix timestamp of the last successful mytonctrl scrape",
+			nil, nil,
+		),
+		pollInterval: pollInterval,
+		snapshotAge: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "snapshot_age_seconds"),
+			"Age of the metrics snapshot served in polling mode",
+			nil, nil,
+		),
+		lastSuccessPoll: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "last_successful_poll_timestamp_seconds"),
+			"Unix timestamp of the last successful poll in polling mode",
+			nil, nil,
+		),
+		mytoncoreRestarts: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "mytoncore_restarts_total"),
+			"Total number of Mytoncore restarts detected from its uptime decreasing",
+			nil, nil,
+		),
+		validatorRestarts: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_restarts_total"),
+			"Total number of Local Validator restarts detected from its uptime decreasing",
+			nil, nil,
+		),
+		mytoncoreStartTime: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "mytoncore_start_time_seconds"),
+			"Unix timestamp Mytoncore started at, derived from its uptime",
+			nil, nil,
+		),
+		validatorStartTime: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_start_time_seconds"),
+			"Unix timestamp the Local Validator started at, derived from its uptime",
+			nil, nil,
+		),
+		walletBalanceChange: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_wallet_balance_change_nanotons"),
+			"Change of the local validator wallet balance in nanotons between the last two scrapes it was read in",
+			nil, nil,
+		),
 	}
 }
 
+// LoadState persists the detected restarts in the state file at path, so they
+// keep counting across exporter restarts, and loads the file when it exists.
+func (collector *MytonCollector) LoadState(path string) error {
+	state, err := loadRestartState(path)
+	if err != nil {
+		return err
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.restarts = state
+	collector.stateFile = path
+
+	return nil
+}
+
 func (collector *MytonCollector) Describe(ch chan<- *prometheus.Desc) {
 	for _, mDef := range collector.metrics {
 		ch <- mDef.desc
 	}
 	ch <- collector.parsingErrors.Desc()
+	collector.failures.Describe(ch)
+	collector.fieldErrors.Describe(ch)
+	ch <- collector.up
+	ch <- collector.scrapeDuration
+	ch <- collector.lastSuccessfulTime
+	ch <- collector.snapshotAge
+	ch <- collector.lastSuccessPoll
+	ch <- collector.mytoncoreRestarts
+	ch <- collector.validatorRestarts
+	ch <- collector.mytoncoreStartTime
+	ch <- collector.validatorStartTime
+	ch <- collector.walletBalanceChange
 }
 
 func (collector *MytonCollector) Collect(ch chan<- prometheus.Metric) {
 	collector.mutex.Lock()
 	defer collector.mutex.Unlock()
 
-	metrics, err := collector.parser.Parse()
+	polling := collector.pollInterval > 0
+	if !polling {
+		collector.scrape(context.Background())
+	}
+
+	if !collector.lastScrapeTime.IsZero() {
+		up := 0.0
+		if collector.lastScrapeOK {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(collector.up, prometheus.GaugeValue, up)
+		ch <- prometheus.MustNewConstMetric(collector.scrapeDuration, prometheus.GaugeValue, collector.lastScrapeDuration.Seconds())
+	}
+
+	if !collector.snapshotTime.IsZero() {
+		ch <- prometheus.MustNewConstMetric(collector.lastSuccessfulTime, prometheus.GaugeValue, float64(collector.snapshotTime.Unix()))
+		if polling {
+			ch <- prometheus.MustNewConstMetric(collector.snapshotAge, prometheus.GaugeValue, collector.now().Sub(collector.snapshotTime).Seconds())
+			ch <- prometheus.MustNewConstMetric(collector.lastSuccessPoll, prometheus.GaugeValue, float64(collector.snapshotTime.Unix()))
+		}
+	}
+
+	// Polling mode keeps serving the last snapshot, its age shows how stale it is.
+	if metrics := collector.snapshot; metrics != nil && (polling || collector.lastScrapeOK) {
+		collectMetrics(ch, collector.metrics, metrics, collector.now())
+
+		snapshotTime := float64(collector.snapshotTime.Unix())
+		if metrics.Parsed("mytoncore_uptime_seconds") {
+			ch <- prometheus.MustNewConstMetric(collector.mytoncoreStartTime, prometheus.GaugeValue,
+				snapshotTime-metrics.MytoncoreUptimeSeconds)
+		}
+		if metrics.Parsed("local_validator_uptime_seconds") {
+			ch <- prometheus.MustNewConstMetric(collector.validatorStartTime, prometheus.GaugeValue,
+				snapshotTime-metrics.LocalValidatorUptimeSeconds)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.mytoncoreRestarts, prometheus.CounterValue, collector.restarts.MytoncoreRestarts)
+	ch <- prometheus.MustNewConstMetric(collector.validatorRestarts, prometheus.CounterValue, collector.restarts.ValidatorRestarts)
+	if collector.balanceChange.known {
+		ch <- prometheus.MustNewConstMetric(collector.walletBalanceChange, prometheus.GaugeValue,
+			float64(collector.balanceChange.change))
+	}
+
+	ch <- collector.parsingErrors
+	collector.failures.Collect(ch)
+	collector.fieldErrors.Collect(ch)
+}
+
+// Run polls mytonctrl every poll interval until ctx is done. Failed polls keep
+// the previous snapshot, so its age shows how stale the served data is.
+func (collector *MytonCollector) Run(ctx context.Context) error {
+	if collector.pollInterval <= 0 {
+		return errors.New("polling is disabled")
+	}
+
+	ticker := time.NewTicker(collector.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		collector.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (collector *MytonCollector) poll(ctx context.Context) {
+	// The mutex is only taken to store the result, so scrapes are served from
+	// the previous snapshot while mytonctrl is running.
+	metrics, start, err := collector.parse(ctx)
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	collector.record(metrics, start, err)
+}
+
+// scrape runs the parser and records its result. The caller must hold the mutex.
+func (collector *MytonCollector) scrape(ctx context.Context) {
+	collector.record(collector.parse(ctx))
+}
+
+func (collector *MytonCollector) parse(ctx context.Context) (*LiteServerMetrics, time.Time, error) {
+	start := collector.now()
+	metrics, err := collector.parser.Parse(ctx)
+	return metrics, start, err
+}
+
+// record stores the result of a parser run started at start. The caller must hold the mutex.
+func (collector *MytonCollector) record(metrics *LiteServerMetrics, start time.Time, err error) {
+	collector.lastScrapeTime = collector.now()
+	collector.lastScrapeDuration = collector.lastScrapeTime.Sub(start)
+	collector.lastScrapeOK = err == nil
+
 	if err != nil {
 		log.Printf("Error collecting metrics: %v", err)
-		collector.parsingErrors.Inc()
+		cause := failureCause(err)
+		if cause != failureCauseTimeout {
+			collector.parsingErrors.Inc()
+		}
+		collector.failures.WithLabelValues(cause).Inc()
 		return
 	}
 
-	for _, mDef := range collector.metrics {
+	for field, ok := range metrics.ParsedFields {
+		if !ok {
+			collector.fieldErrors.WithLabelValues(field).Inc()
+		}
+	}
+
+	collector.snapshot = metrics
+	collector.snapshotTime = collector.lastScrapeTime
+
+	collector.balanceChange.observe(metrics)
+	if collector.restarts.observe(metrics) && collector.stateFile != "" {
+		if err := collector.restarts.save(collector.stateFile); err != nil {
+			log.Printf("Error saving state: %v", err)
+		}
+	}
+}
+
+// collectMetrics sends the values of the metric definitions for the parsed metrics
+// as of now.
+func collectMetrics(ch chan<- prometheus.Metric, defs []MetricDef, metrics *LiteServerMetrics, now time.Time) {
+	for _, mDef := range defs {
+		if mDef.field != "" && !metrics.Parsed(mDef.field) {
+			continue
+		}
+		valueType := mDef.valueType
+		if valueType == 0 {
+			valueType = prometheus.GaugeValue
+		}
+		if mDef.stateSet != nil {
+			for _, v := range mDef.stateSet.values(metrics, now) {
+				ch <- prometheus.MustNewConstMetric(mDef.desc, valueType, v.value, v.labels...)
+			}
+			continue
+		}
+		if mDef.getValuesAt != nil {
+			for _, v := range mDef.getValuesAt(metrics, now) {
+				ch <- prometheus.MustNewConstMetric(mDef.desc, valueType, v.value, v.labels...)
+			}
+			continue
+		}
+		if mDef.getValues != nil {
+			for _, v := range mDef.getValues(metrics) {
+				ch <- prometheus.MustNewConstMetric(mDef.desc, valueType, v.value, v.labels...)
+			}
+			continue
+		}
 		value, labels := mDef.getValue(metrics)
-		ch <- prometheus.MustNewConstMetric(mDef.desc, prometheus.GaugeValue, value, labels...)
+		ch <- prometheus.MustNewConstMetric(mDef.desc, valueType, value, labels...)
 	}
+}
 
-	ch <- collector.parsingErrors
+// failureCause maps a Parse error to the scrape failures counter label.
+func failureCause(err error) string {
+	switch {
+	case errors.Is(err, ErrTimeout):
+		return failureCauseTimeout
+	case errors.Is(err, ErrUnavailable):
+		return failureCauseUnavailable
+	case errors.Is(err, ErrExitCode):
+		return failureCauseExitCode
+	case errors.Is(err, ErrParse):
+		return failureCauseParse
+	default:
+		return failureCauseExec
+	}
 }
@@ -0,0 +1,592 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type staticSource struct {
+	output string
+	err    error
+}
+
+func (s staticSource) Fetch(_ context.Context) (string, error) {
+	return s.output, s.err
+}
+
+func TestMytonCollector_Collect(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		want   string
+	}{
+		{
+			name:   "successful scrape",
+			source: staticSource{output: "Network name: testnet\nNumber of validators: 23(26)\n"},
+			want: `
+# HELP ton_liteserver_exporter_all_validators Total number of validators
+# TYPE ton_liteserver_exporter_all_validators gauge
+ton_liteserver_exporter_all_validators 26
+# HELP ton_liteserver_exporter_scrape_failures_total Total number of failed mytonctrl scrapes by cause
+# TYPE ton_liteserver_exporter_scrape_failures_total counter
+ton_liteserver_exporter_scrape_failures_total{cause="exec"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="exit_code"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="parse"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="timeout"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="unavailable"} 0
+# HELP ton_liteserver_exporter_up Whether the last mytonctrl scrape was successful
+# TYPE ton_liteserver_exporter_up gauge
+ton_liteserver_exporter_up 1
+`,
+		},
+		{
+			name:   "parse failure",
+			source: staticSource{},
+			want: `
+# HELP ton_liteserver_exporter_scrape_failures_total Total number of failed mytonctrl scrapes by cause
+# TYPE ton_liteserver_exporter_scrape_failures_total counter
+ton_liteserver_exporter_scrape_failures_total{cause="exec"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="exit_code"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="parse"} 1
+ton_liteserver_exporter_scrape_failures_total{cause="timeout"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="unavailable"} 0
+# HELP ton_liteserver_exporter_up Whether the last mytonctrl scrape was successful
+# TYPE ton_liteserver_exporter_up gauge
+ton_liteserver_exporter_up 0
+`,
+		},
+		{
+			name:   "timeout",
+			source: staticSource{err: ErrTimeout},
+			want: `
+# HELP ton_liteserver_exporter_scrape_failures_total Total number of failed mytonctrl scrapes by cause
+# TYPE ton_liteserver_exporter_scrape_failures_total counter
+ton_liteserver_exporter_scrape_failures_total{cause="exec"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="exit_code"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="parse"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="timeout"} 1
+ton_liteserver_exporter_scrape_failures_total{cause="unavailable"} 0
+# HELP ton_liteserver_exporter_up Whether the last mytonctrl scrape was successful
+# TYPE ton_liteserver_exporter_up gauge
+ton_liteserver_exporter_up 0
+`,
+		},
+		{
+			name:   "unavailable",
+			source: staticSource{err: fmt.Errorf("docker source: %w: Error: No such container: ton", ErrUnavailable)},
+			want: `
+# HELP ton_liteserver_exporter_scrape_failures_total Total number of failed mytonctrl scrapes by cause
+# TYPE ton_liteserver_exporter_scrape_failures_total counter
+ton_liteserver_exporter_scrape_failures_total{cause="exec"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="exit_code"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="parse"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="timeout"} 0
+ton_liteserver_exporter_scrape_failures_total{cause="unavailable"} 1
+# HELP ton_liteserver_exporter_up Whether the last mytonctrl scrape was successful
+# TYPE ton_liteserver_exporter_up gauge
+ton_liteserver_exporter_up 0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewMytonCollector(NewParser(tt.source, 0, ParserOptions{}), 0)
+			err := testutil.CollectAndCompare(collector, strings.NewReader(tt.want),
+				"ton_liteserver_exporter_all_validators",
+				"ton_liteserver_exporter_scrape_failures_total",
+				"ton_liteserver_exporter_up",
+			)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+const fullStatusOutput = `
+===[ TON network status ]===
+Network name: testnet
+Number of validators: 23(24)
+Number of shardchains: 4
+Number of offers: 0(0)
+Number of complaints: 0(0)
+Election status: closed
+===[ Node status ]===
+Validator index: -1
+ADNL address of local validator: D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932
+Public ADNL address of node: BFE38D4B6B7CAB1FB4396068590C26857A400F93D2F4693208D181B7279B0348
+Local validator wallet address: kf_1160NJDnTqgz9AJ6p7EweUP_15xaZw1PK4WPcd48tDbQ1
+Local validator wallet balance: 95290.938201014
+Load average[16]: 0.47, 0.4, 0.37
+Network load average (Mbit/s): 24.61, 23.6, 23.59
+Memory load: ram: [62.17 Gb, 47.5%], swap: [0.0 Gb, 0.2%]
+Disks load average (MB/s): vda: [0.18, 0.47%], vdc: [0.54, 4.28%]
+Mytoncore status: working, 14 days
+Local validator status: working, 16 days
+Local validator out of sync: 3 s
+Local validator last state serialization: 3 blocks ago
+Local validator database size: 25.89 Gb, 2.4%
+Version mytonctrl: 74536b (master)
+Version validator: 0c21ce2 (master)
+===[ TON network configuration ]===
+Configurator address: -1:5555555555555555555555555555555555555555555555555555555555555555
+Elector address: -1:3333333333333333333333333333333333333333333333333333333333333333
+Validation period: 7200, Duration of elections: 2400-180, Hold period: 900
+Minimum stake: 10000.0, Maximum stake: 5000000.0
+===[ TON timestamps ]===
+TON network was launched: 15.11.2019 12:44:14 UTC
+Start of the validation cycle: 24.09.2024 06:19:55 UTC
+End of the validation cycle: 24.09.2024 08:19:55 UTC
+Start of elections: 24.09.2024 05:39:55 UTC
+End of elections: 24.09.2024 06:16:55 UTC
+Beginning of the next elections: 24.09.2024 07:39:55 UTC
+`
+
+func TestMetrics_AllExportedForFullOutput(t *testing.T) {
+	metrics, err := NewParser(nil, 0, ParserOptions{}).ParseOutput(fullStatusOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields that are only read by the mytoncore db source or the optional passes.
+	notInStatusOutput := map[string]bool{
+		"past_stakes":                        true,
+		"latest_participated_election_id":    true,
+		"validator_efficiency_ratio":         true,
+		"blocks_created":                     true,
+		"blocks_expected":                    true,
+		"validators":                         true,
+		"offers":                             true,
+		"complaints":                         true,
+		"complaints_against_local_validator": true,
+		"local_validator_stake_tons":         true,
+	}
+
+	for _, mDef := range Metrics {
+		// The watched accounts, pools and stake state metrics are not gated by a field, they are only read by
+		// their passes.
+		name := mDef.desc.String()
+		if notInStatusOutput[mDef.field] || strings.Contains(name, `"ton_liteserver_exporter_account_`) ||
+			strings.Contains(name, `"ton_liteserver_exporter_pool_`) ||
+			strings.Contains(name, `"ton_liteserver_exporter_local_validator_stake_state"`) {
+			continue
+		}
+		ch := make(chan prometheus.Metric, 16)
+		collectMetrics(ch, []MetricDef{mDef}, &metrics, time.Date(2024, 9, 24, 7, 0, 0, 0, time.UTC))
+		close(ch)
+		if len(ch) == 0 {
+			t.Errorf("metric %s is not exported", mDef.desc)
+		}
+	}
+}
+
+func TestMytonCollector_CollectFieldErrors(t *testing.T) {
+	source := staticSource{output: "Number of validators: many\nNumber of shardchains: 4\n"}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+
+	want := `
+# HELP ton_liteserver_exporter_field_parse_errors_total Total number of fields present in the mytonctrl output whose value could not be parsed
+# TYPE ton_liteserver_exporter_field_parse_errors_total counter
+ton_liteserver_exporter_field_parse_errors_total{field="all_validators"} 1
+ton_liteserver_exporter_field_parse_errors_total{field="online_validators"} 1
+# HELP ton_liteserver_exporter_number_of_shardchains Number of shardchains
+# TYPE ton_liteserver_exporter_number_of_shardchains gauge
+ton_liteserver_exporter_number_of_shardchains 4
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_field_parse_errors_total",
+		"ton_liteserver_exporter_number_of_shardchains",
+		"ton_liteserver_exporter_online_validators",
+		"ton_liteserver_exporter_validator_index",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMytonCollector_CollectStateSets(t *testing.T) {
+	source := staticSource{output: "Mytoncore status: restarting, 1 days\nLocal validator status: not working, 2 hours\n"}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+
+	want := `
+# HELP ton_liteserver_exporter_election_status Election status (open/closed/unknown)
+# TYPE ton_liteserver_exporter_election_status gauge
+ton_liteserver_exporter_election_status{status="closed"} 0
+ton_liteserver_exporter_election_status{status="open"} 0
+ton_liteserver_exporter_election_status{status="unknown"} 1
+# HELP ton_liteserver_exporter_local_validator_status Status of Local Validator (working/not working/unknown)
+# TYPE ton_liteserver_exporter_local_validator_status gauge
+ton_liteserver_exporter_local_validator_status{status="not working"} 1
+ton_liteserver_exporter_local_validator_status{status="unknown"} 0
+ton_liteserver_exporter_local_validator_status{status="working"} 0
+# HELP ton_liteserver_exporter_mytoncore_status Status of Mytoncore (working/not working/unknown)
+# TYPE ton_liteserver_exporter_mytoncore_status gauge
+ton_liteserver_exporter_mytoncore_status{status="not working"} 0
+ton_liteserver_exporter_mytoncore_status{status="unknown"} 1
+ton_liteserver_exporter_mytoncore_status{status="working"} 0
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_election_status",
+		"ton_liteserver_exporter_local_validator_status",
+		"ton_liteserver_exporter_mytoncore_status",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if label, ok := StateSetLabel("ton_liteserver_exporter_mytoncore_status"); !ok || label != "status" {
+		t.Errorf("StateSetLabel() = %q, %v, want %q, true", label, ok, "status")
+	}
+}
+
+func TestMytonCollector_CollectStakeState(t *testing.T) {
+	source := runnerSource{
+		status: "Election status: open\n" +
+			"ADNL address of local validator: AA11\n" +
+			"Validation period: 65536, Duration of elections: 32768-8192, Hold period: 32768\n" +
+			"End of the validation cycle: 24.09.2024 05:39:55 UTC\n",
+		commands: map[string]string{electionEntriesCommand: `{}`},
+	}
+	parser := NewParser(source, 0, ParserOptions{ElectionEntries: true})
+	parser.now = func() time.Time { return time.Unix(1727156395, 0) }
+	collector := NewMytonCollector(parser, 0)
+	name := "ton_liteserver_exporter_local_validator_stake_state"
+	want := func(frozen, returned, unknown int) string {
+		return fmt.Sprintf(`
+# HELP ton_liteserver_exporter_local_validator_stake_state State of the local validator stake (frozen/returned/unknown)
+# TYPE ton_liteserver_exporter_local_validator_stake_state gauge
+ton_liteserver_exporter_local_validator_stake_state{state="frozen"} %d
+ton_liteserver_exporter_local_validator_stake_state{state="returned"} %d
+ton_liteserver_exporter_local_validator_stake_state{state="unknown"} %d
+`, frozen, returned, unknown)
+	}
+
+	// Every state keeps its series, before and after a stake was seen.
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(0, 0, 1)), name); err != nil {
+		t.Error(err)
+	}
+	source.commands[electionEntriesCommand] = `{"AA11": {"adnlAddr": "AA11", "stake": 350000, "walletAddr": "Ef11"}}`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(1, 0, 0)), name); err != nil {
+		t.Error(err)
+	}
+
+	// Without the election entries pass the state set is left out.
+	collector = NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(""), name); err != nil {
+		t.Error(err)
+	}
+}
+
+// sequenceSource returns its outputs in turn, repeating the last one.
+type sequenceSource struct {
+	outputs []string
+	// errs fails the fetches with a non-nil error at the same index, when set.
+	errs  []error
+	calls *int
+}
+
+func (s sequenceSource) Fetch(_ context.Context) (string, error) {
+	i := min(*s.calls, len(s.outputs)-1)
+	*s.calls++
+	if i < len(s.errs) && s.errs[i] != nil {
+		return "", s.errs[i]
+	}
+	return s.outputs[i], nil
+}
+
+func TestMytonCollector_CollectStartTime(t *testing.T) {
+	source := staticSource{output: "Mytoncore status: working, 14 days\nLocal validator status: working, 45 seconds\n"}
+	now := time.Date(2024, 9, 24, 7, 0, 0, 0, time.UTC)
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+	collector.now = func() time.Time { return now }
+
+	names := []string{
+		"ton_liteserver_exporter_mytoncore_start_time_seconds",
+		"ton_liteserver_exporter_validator_start_time_seconds",
+	}
+	want := func(mytoncore, validator time.Time) string {
+		return fmt.Sprintf(`
+# HELP ton_liteserver_exporter_mytoncore_start_time_seconds Unix timestamp Mytoncore started at, derived from its uptime
+# TYPE ton_liteserver_exporter_mytoncore_start_time_seconds gauge
+ton_liteserver_exporter_mytoncore_start_time_seconds %d
+# HELP ton_liteserver_exporter_validator_start_time_seconds Unix timestamp the Local Validator started at, derived from its uptime
+# TYPE ton_liteserver_exporter_validator_start_time_seconds gauge
+ton_liteserver_exporter_validator_start_time_seconds %d
+`, mytoncore.Unix(), validator.Unix())
+	}
+
+	// The start time is the snapshot time minus the uptime.
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(
+		now.Add(-14*24*time.Hour), now.Add(-45*time.Second),
+	)), names...); err != nil {
+		t.Error(err)
+	}
+
+	// An uptime printed in days stays the same for a day, so the start time
+	// derived from it moves with the snapshot time within that precision.
+	now = now.Add(time.Hour)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(
+		now.Add(-14*24*time.Hour), now.Add(-45*time.Second),
+	)), names...); err != nil {
+		t.Error(err)
+	}
+
+	// Without an uptime the start times are left out.
+	source.output = "Mytoncore status: working\n"
+	collector = NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+	collector.now = func() time.Time { return now }
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(""), names...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMytonCollector_Poll(t *testing.T) {
+	source := sequenceSource{
+		outputs: []string{"Number of validators: 23(26)\n", ""},
+		errs:    []error{nil, fmt.Errorf("%w: connection refused", ErrExec)},
+		calls:   new(int),
+	}
+	now := time.Date(2024, 9, 24, 7, 0, 0, 0, time.UTC)
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), time.Minute)
+	collector.now = func() time.Time { return now }
+
+	names := []string{
+		"ton_liteserver_exporter_all_validators",
+		"ton_liteserver_exporter_up",
+		"ton_liteserver_exporter_snapshot_age_seconds",
+		"ton_liteserver_exporter_last_successful_poll_timestamp_seconds",
+	}
+	want := func(up, age int) string {
+		return fmt.Sprintf(`
+# HELP ton_liteserver_exporter_all_validators Total number of validators
+# TYPE ton_liteserver_exporter_all_validators gauge
+ton_liteserver_exporter_all_validators 26
+# HELP ton_liteserver_exporter_last_successful_poll_timestamp_seconds Unix timestamp of the last successful poll in polling mode
+# TYPE ton_liteserver_exporter_last_successful_poll_timestamp_seconds gauge
+ton_liteserver_exporter_last_successful_poll_timestamp_seconds 1.7271612e+09
+# HELP ton_liteserver_exporter_snapshot_age_seconds Age of the metrics snapshot served in polling mode
+# TYPE ton_liteserver_exporter_snapshot_age_seconds gauge
+ton_liteserver_exporter_snapshot_age_seconds %d
+# HELP ton_liteserver_exporter_up Whether the last mytonctrl scrape was successful
+# TYPE ton_liteserver_exporter_up gauge
+ton_liteserver_exporter_up %d
+`, age, up)
+	}
+
+	// Nothing is served before the first poll, scrapes do not run mytonctrl.
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(""), names...); err != nil {
+		t.Error(err)
+	}
+	if *source.calls != 0 {
+		t.Errorf("scrape fetched the source %d times, want 0", *source.calls)
+	}
+
+	collector.poll(context.Background())
+	now = now.Add(10 * time.Second)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(1, 10)), names...); err != nil {
+		t.Error(err)
+	}
+
+	// A failed poll keeps serving the previous snapshot, which keeps aging.
+	now = now.Add(50 * time.Second)
+	collector.poll(context.Background())
+	now = now.Add(5 * time.Second)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(0, 65)), names...); err != nil {
+		t.Error(err)
+	}
+	if *source.calls != 2 {
+		t.Errorf("source fetched %d times, want 2", *source.calls)
+	}
+}
+
+func TestMytonCollector_Run(t *testing.T) {
+	if err := NewMytonCollector(NewParser(staticSource{}, 0, ParserOptions{}), 0).Run(context.Background()); err == nil {
+		t.Error("Run() with polling disabled succeeded")
+	}
+
+	source := sequenceSource{outputs: []string{"Number of validators: 23(26)\n"}, calls: new(int)}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Run polls once right away and returns once ctx is done.
+	if err := collector.Run(ctx); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if *source.calls != 1 {
+		t.Errorf("Run() fetched the source %d times, want 1", *source.calls)
+	}
+}
+
+func TestMytonCollector_CollectAccounts(t *testing.T) {
+	source := runnerSource{
+		status: "Network name: mainnet\n",
+		commands: map[string]string{
+			"vas EQPool": "Address  Status  Balance  Version\nEQPool  frozen  12.5  v1\n",
+		},
+	}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{
+		Accounts: []WatchedAccount{{Address: "EQPool", Alias: "pool"}},
+	}), 0)
+
+	want := `
+# HELP ton_liteserver_exporter_account_balance Balance of a watched account in TONs
+# TYPE ton_liteserver_exporter_account_balance gauge
+ton_liteserver_exporter_account_balance{address="EQPool",alias="pool"} 12.5
+# HELP ton_liteserver_exporter_account_status State of a watched account (active/uninit/frozen/empty/unknown)
+# TYPE ton_liteserver_exporter_account_status gauge
+ton_liteserver_exporter_account_status{address="EQPool",state="active"} 0
+ton_liteserver_exporter_account_status{address="EQPool",state="empty"} 0
+ton_liteserver_exporter_account_status{address="EQPool",state="frozen"} 1
+ton_liteserver_exporter_account_status{address="EQPool",state="uninit"} 0
+ton_liteserver_exporter_account_status{address="EQPool",state="unknown"} 0
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_account_balance", "ton_liteserver_exporter_account_status")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMytonCollector_CollectPools(t *testing.T) {
+	source := runnerSource{
+		status: "Network name: mainnet\n",
+		commands: map[string]string{
+			poolsListCommand: "Name  Status  Balance  Version   Address\nsingle  frozen  12.5  spool_r2  EQSingle\n",
+		},
+	}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{Pools: true}), 0)
+
+	want := `
+# HELP ton_liteserver_exporter_pool_balance_tons Balance of a nominator pool in TONs
+# TYPE ton_liteserver_exporter_pool_balance_tons gauge
+ton_liteserver_exporter_pool_balance_tons{address="EQSingle"} 12.5
+# HELP ton_liteserver_exporter_pool_state State of a nominator pool (active/stopped/unknown)
+# TYPE ton_liteserver_exporter_pool_state gauge
+ton_liteserver_exporter_pool_state{address="EQSingle",state="active"} 0
+ton_liteserver_exporter_pool_state{address="EQSingle",state="stopped"} 1
+ton_liteserver_exporter_pool_state{address="EQSingle",state="unknown"} 0
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_pool_balance_tons",
+		"ton_liteserver_exporter_pool_nominators",
+		"ton_liteserver_exporter_pool_state",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMytonCollector_CollectOffers(t *testing.T) {
+	source := runnerSource{
+		status: "Network name: mainnet\n",
+		commands: map[string]string{
+			offersListCommand:     `[{"hash": "H1", "config": {"id": 17}, "votedValidators": [0, 4], "approvedPercent": 42.5, "isPassed": false}]`,
+			complaintsListCommand: "{}",
+		},
+	}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{OffersComplaints: true}), 0)
+
+	// Every offer metric has the same labels, so they can be joined on param.
+	want := `
+# HELP ton_liteserver_exporter_offer_approved_percent Approval of the open offer in percent of the validator weight
+# TYPE ton_liteserver_exporter_offer_approved_percent gauge
+ton_liteserver_exporter_offer_approved_percent{hash="H1",param="17"} 42.5
+# HELP ton_liteserver_exporter_offer_config_param ID of the config param changed by the open offer
+# TYPE ton_liteserver_exporter_offer_config_param gauge
+ton_liteserver_exporter_offer_config_param{hash="H1",param="17"} 17
+# HELP ton_liteserver_exporter_offer_votes Number of validators that voted for the open offer
+# TYPE ton_liteserver_exporter_offer_votes gauge
+ton_liteserver_exporter_offer_votes{hash="H1",param="17"} 2
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_offer_approved_percent",
+		"ton_liteserver_exporter_offer_config_param",
+		"ton_liteserver_exporter_offer_votes",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMytonCollector_CollectBalanceChange(t *testing.T) {
+	source := sequenceSource{
+		outputs: []string{
+			"Local validator wallet balance: 95290.938201014\n",
+			"Local validator wallet balance: 95290.938201015\n",
+			"Local validator wallet balance: 90290.5\n",
+		},
+		calls: new(int),
+	}
+	collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+
+	want := func(change string) string {
+		if change == "" {
+			return ""
+		}
+		return `
+# HELP ton_liteserver_exporter_local_validator_wallet_balance_change_nanotons Change of the local validator wallet balance in nanotons between the last two scrapes it was read in
+# TYPE ton_liteserver_exporter_local_validator_wallet_balance_change_nanotons gauge
+ton_liteserver_exporter_local_validator_wallet_balance_change_nanotons ` + change + "\n"
+	}
+	name := "ton_liteserver_exporter_local_validator_wallet_balance_change_nanotons"
+
+	// The change is only known from the second scrape on.
+	for _, change := range []string{"", "1", "-5.000438201015e+12"} {
+		if err := testutil.CollectAndCompare(collector, strings.NewReader(want(change)), name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestMytonCollector_CollectRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	source := sequenceSource{
+		outputs: []string{
+			"Mytoncore status: working, 3 hours\nLocal validator status: working, 2 days\n",
+			"Mytoncore status: working, 5 minutes\nLocal validator status: working, 2 days\n",
+		},
+		calls: new(int),
+	}
+
+	newCollector := func() *MytonCollector {
+		collector := NewMytonCollector(NewParser(source, 0, ParserOptions{}), 0)
+		if err := collector.LoadState(path); err != nil {
+			t.Fatal(err)
+		}
+		return collector
+	}
+
+	want := func(mytoncore, validator int) string {
+		return fmt.Sprintf(`
+# HELP ton_liteserver_exporter_mytoncore_restarts_total Total number of Mytoncore restarts detected from its uptime decreasing
+# TYPE ton_liteserver_exporter_mytoncore_restarts_total counter
+ton_liteserver_exporter_mytoncore_restarts_total %d
+# HELP ton_liteserver_exporter_validator_restarts_total Total number of Local Validator restarts detected from its uptime decreasing
+# TYPE ton_liteserver_exporter_validator_restarts_total counter
+ton_liteserver_exporter_validator_restarts_total %d
+`, mytoncore, validator)
+	}
+	names := []string{"ton_liteserver_exporter_mytoncore_restarts_total", "ton_liteserver_exporter_validator_restarts_total"}
+
+	collector := newCollector()
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(0, 0)), names...); err != nil {
+		t.Error(err)
+	}
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(want(1, 0)), names...); err != nil {
+		t.Error(err)
+	}
+
+	// The restarts and the last uptimes are restored from the state file.
+	if err := testutil.CollectAndCompare(newCollector(), strings.NewReader(want(1, 0)), names...); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,86 @@
+package collector
+
+import "time"
+
+// Phases of the election and validation cycle exported by the cycle_phase metric.
+const (
+	cyclePhaseElectionsOpen          = "elections_open"
+	cyclePhaseElectionsClosedWaiting = "elections_closed_waiting"
+	cyclePhaseValidating             = "validating"
+	cyclePhaseHold                   = "hold"
+)
+
+// cyclePhases lists every phase, cycle_phase exports all of them.
+var cyclePhases = []string{
+	cyclePhaseElectionsOpen,
+	cyclePhaseElectionsClosedWaiting,
+	cyclePhaseValidating,
+	cyclePhaseHold,
+}
+
+// cyclePhase derives the phase of the election and validation cycle at now
+// from the timestamps of the current cycle and the election status:
+//   - elections_open: the elections for the current or the next cycle accept stakes;
+//   - elections_closed_waiting: the elections ended and the elected cycle has not started;
+//   - validating: the current cycle runs and no elections are in progress;
+//   - hold: the cycle ended and its stakes are frozen for the hold period.
+//
+// It reports false when the timestamps are missing or now is past the hold period.
+func cyclePhase(m *LiteServerMetrics, now time.Time) (string, bool) {
+	if !m.Parsed("start_validation_cycle_timestamp") || !m.Parsed("end_validation_cycle_timestamp") {
+		return "", false
+	}
+
+	t := float64(now.Unix())
+	open, statusKnown := m.ElectionStatus == "open", m.Parsed("election_status")
+	if statusKnown && open {
+		return cyclePhaseElectionsOpen, true
+	}
+
+	// Elections of the current cycle, before it started.
+	if m.Parsed("start_elections_timestamp") && m.Parsed("end_elections_timestamp") {
+		if !statusKnown && t >= m.StartElectionsTimestamp && t < m.EndElectionsTimestamp {
+			return cyclePhaseElectionsOpen, true
+		}
+		if t >= m.EndElectionsTimestamp && t < m.StartValidationCycleTimestamp {
+			return cyclePhaseElectionsClosedWaiting, true
+		}
+
+		// Elections of the next cycle last as long as the ones of the current cycle.
+		if m.Parsed("begin_next_elections_timestamp") {
+			nextEnd := m.BeginNextElectionsTimestamp + (m.EndElectionsTimestamp - m.StartElectionsTimestamp)
+			if !statusKnown && t >= m.BeginNextElectionsTimestamp && t < nextEnd {
+				return cyclePhaseElectionsOpen, true
+			}
+			if t >= nextEnd && t < m.EndValidationCycleTimestamp {
+				return cyclePhaseElectionsClosedWaiting, true
+			}
+		}
+	}
+
+	switch {
+	case t >= m.StartValidationCycleTimestamp && t < m.EndValidationCycleTimestamp:
+		return cyclePhaseValidating, true
+	case t >= m.EndValidationCycleTimestamp && m.Parsed("hold_period_seconds") &&
+		t < m.EndValidationCycleTimestamp+m.HoldPeriodSeconds:
+		return cyclePhaseHold, true
+	default:
+		return "", false
+	}
+}
+
+// secondsUntil returns the seconds from now to the timestamp, 0 once it passed.
+func secondsUntil(timestamp float64, now time.Time) float64 {
+	return max(timestamp-float64(now.UnixNano())/float64(time.Second), 0)
+}
+
+// cycleProgress returns the elapsed part of the current cycle at now, from 0 to 1.
+func cycleProgress(m *LiteServerMetrics, now time.Time) (float64, bool) {
+	length := m.EndValidationCycleTimestamp - m.StartValidationCycleTimestamp
+	if !m.Parsed("start_validation_cycle_timestamp") || !m.Parsed("end_validation_cycle_timestamp") || length <= 0 {
+		return 0, false
+	}
+
+	elapsed := float64(now.UnixNano())/float64(time.Second) - m.StartValidationCycleTimestamp
+	return min(max(elapsed/length, 0), 1), true
+}
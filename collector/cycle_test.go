@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// cycleStatusOutput is a cycle from 06:19:55 to 08:19:55 with its elections
+// from 05:39:55 to 06:16:55 and the next ones from 07:39:55 to 08:16:55.
+const cycleStatusOutput = `
+Validation period: 7200, Duration of elections: 2400-180, Hold period: 900
+Start of the validation cycle: 24.09.2024 06:19:55 UTC
+End of the validation cycle: 24.09.2024 08:19:55 UTC
+Start of elections: 24.09.2024 05:39:55 UTC
+End of elections: 24.09.2024 06:16:55 UTC
+Beginning of the next elections: 24.09.2024 07:39:55 UTC
+`
+
+func at(hour, minute, second int) time.Time {
+	return time.Date(2024, 9, 24, hour, minute, second, 0, time.UTC)
+}
+
+func TestCyclePhase(t *testing.T) {
+	metrics, err := NewParser(nil, 0, ParserOptions{}).ParseOutput(cycleStatusOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		now            time.Time
+		electionStatus string
+		wantPhase      string
+		wantOK         bool
+		wantProgress   float64
+	}{
+		{name: "elections of the cycle", now: at(6, 0, 0), wantPhase: cyclePhaseElectionsOpen, wantOK: true},
+		{name: "elected cycle not started", now: at(6, 18, 0), wantPhase: cyclePhaseElectionsClosedWaiting, wantOK: true},
+		{name: "cycle start", now: at(6, 19, 55), wantPhase: cyclePhaseValidating, wantOK: true},
+		{name: "validating", now: at(7, 19, 55), wantPhase: cyclePhaseValidating, wantOK: true, wantProgress: 0.5},
+		{name: "next elections", now: at(7, 40, 0), wantPhase: cyclePhaseElectionsOpen, wantOK: true, wantProgress: 4805.0 / 7200},
+		{
+			name:           "next elections closed by status",
+			now:            at(7, 40, 0),
+			electionStatus: "closed",
+			wantPhase:      cyclePhaseValidating,
+			wantOK:         true,
+			wantProgress:   4805.0 / 7200,
+		},
+		{
+			name:           "open by status",
+			now:            at(7, 0, 0),
+			electionStatus: "open",
+			wantPhase:      cyclePhaseElectionsOpen,
+			wantOK:         true,
+			wantProgress:   2405.0 / 7200,
+		},
+		{name: "next elections ended", now: at(8, 17, 0), wantPhase: cyclePhaseElectionsClosedWaiting, wantOK: true, wantProgress: 7025.0 / 7200},
+		{name: "hold", now: at(8, 30, 0), wantPhase: cyclePhaseHold, wantOK: true, wantProgress: 1},
+		{name: "after hold", now: at(8, 40, 0), wantOK: false, wantProgress: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics
+			m.ParsedFields = make(map[string]bool, len(metrics.ParsedFields))
+			for field, ok := range metrics.ParsedFields {
+				m.ParsedFields[field] = ok
+			}
+			if tt.electionStatus != "" {
+				m.ElectionStatus = tt.electionStatus
+				m.setField("election_status", true)
+			}
+
+			phase, ok := cyclePhase(&m, tt.now)
+			if phase != tt.wantPhase || ok != tt.wantOK {
+				t.Errorf("cyclePhase() = %q, %v, want %q, %v", phase, ok, tt.wantPhase, tt.wantOK)
+			}
+			if progress, _ := cycleProgress(&m, tt.now); progress != tt.wantProgress {
+				t.Errorf("cycleProgress() = %v, want %v", progress, tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestMytonCollector_CollectCycleUnknown(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		now    time.Time
+	}{
+		{name: "no timestamps", output: "Network name: mainnet\n", now: at(7, 0, 0)},
+		{name: "no end of the cycle", output: "Start of the validation cycle: 24.09.2024 06:19:55 UTC\n", now: at(7, 0, 0)},
+		{name: "after hold", output: cycleStatusOutput, now: at(8, 40, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewMytonCollector(NewParser(staticSource{output: tt.output}, 0, ParserOptions{}), 0)
+			collector.now = func() time.Time { return tt.now }
+
+			// The phase is left out instead of exporting every state as 0.
+			err := testutil.CollectAndCompare(collector, strings.NewReader(""), "ton_liteserver_exporter_cycle_phase")
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMytonCollector_CollectCycle(t *testing.T) {
+	collector := NewMytonCollector(NewParser(staticSource{output: cycleStatusOutput}, 0, ParserOptions{}), 0)
+	collector.now = func() time.Time { return at(7, 19, 55) }
+
+	want := `
+# HELP ton_liteserver_exporter_cycle_phase Phase of the election and validation cycle (elections_open/elections_closed_waiting/validating/hold)
+# TYPE ton_liteserver_exporter_cycle_phase gauge
+ton_liteserver_exporter_cycle_phase{phase="elections_closed_waiting"} 0
+ton_liteserver_exporter_cycle_phase{phase="elections_open"} 0
+ton_liteserver_exporter_cycle_phase{phase="hold"} 0
+ton_liteserver_exporter_cycle_phase{phase="validating"} 1
+# HELP ton_liteserver_exporter_cycle_progress_ratio Elapsed part of the validation cycle, from 0 to 1
+# TYPE ton_liteserver_exporter_cycle_progress_ratio gauge
+ton_liteserver_exporter_cycle_progress_ratio 0.5
+# HELP ton_liteserver_exporter_seconds_until_cycle_end Seconds until the end of the validation cycle, 0 once it ended
+# TYPE ton_liteserver_exporter_seconds_until_cycle_end gauge
+ton_liteserver_exporter_seconds_until_cycle_end 3600
+# HELP ton_liteserver_exporter_seconds_until_next_elections Seconds until the beginning of the next elections, 0 once they began
+# TYPE ton_liteserver_exporter_seconds_until_next_elections gauge
+ton_liteserver_exporter_seconds_until_next_elections 1200
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"ton_liteserver_exporter_cycle_phase",
+		"ton_liteserver_exporter_cycle_progress_ratio",
+		"ton_liteserver_exporter_seconds_until_cycle_end",
+		"ton_liteserver_exporter_seconds_until_next_elections",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Precisions of the durations printed by mytonctrl, the smallest unit they were
+// printed with. They are exported as the "precision" label of duration metrics.
+const (
+	precisionSecond = "second"
+	precisionMinute = "minute"
+	precisionHour   = "hour"
+	precisionDay    = "day"
+	precisionWeek   = "week"
+)
+
+// durationUnit is a unit a duration may be printed in.
+type durationUnit struct {
+	seconds   float64
+	precision string
+}
+
+// durationUnits maps the unit names, abbreviations, plurals and translations
+// used by mytonctrl to their unit.
+var durationUnits = func() map[string]durationUnit {
+	units := make(map[string]durationUnit)
+	for _, unit := range []struct {
+		durationUnit
+		names []string
+	}{
+		{
+			durationUnit: durationUnit{seconds: 1, precision: precisionSecond},
+			names: []string{
+				"s", "sec", "secs", "second", "seconds",
+				"с", "сек", "секунда", "секунды", "секунд", "秒", "秒钟",
+			},
+		},
+		{
+			durationUnit: durationUnit{seconds: 60, precision: precisionMinute},
+			names: []string{
+				"m", "min", "mins", "minute", "minutes",
+				"м", "мин", "минута", "минуты", "минут", "分", "分钟",
+			},
+		},
+		{
+			durationUnit: durationUnit{seconds: 3600, precision: precisionHour},
+			names: []string{
+				"h", "hr", "hrs", "hour", "hours",
+				"ч", "час", "часа", "часов", "小时", "时",
+			},
+		},
+		{
+			durationUnit: durationUnit{seconds: 86400, precision: precisionDay},
+			names: []string{
+				"d", "day", "days",
+				"д", "дн", "день", "дня", "дней", "天", "日",
+			},
+		},
+		{
+			durationUnit: durationUnit{seconds: 604800, precision: precisionWeek},
+			names: []string{
+				"w", "week", "weeks",
+				"нед", "неделя", "недели", "недель", "周", "星期",
+			},
+		},
+	} {
+		for _, name := range unit.names {
+			units[name] = unit.durationUnit
+		}
+	}
+	return units
+}()
+
+var (
+	durationPart = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(\p{L}+)\.?`)
+	// durationSeparator matches what may appear between the parts of a compound duration.
+	durationSeparator = regexp.MustCompile(`^(?:[\s,]|and|и)*$`)
+)
+
+// parseDuration parses a duration printed by mytonctrl, such as "16 days",
+// "1 day 3 hours", "2d, 4h", "5 мин" or a bare number of seconds. It returns
+// the duration in seconds and the precision it was printed with.
+func parseDuration(value string) (float64, string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return seconds, precisionSecond, true
+	}
+
+	matches := durationPart.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return 0, "", false
+	}
+
+	var (
+		total     float64
+		precision = durationUnit{seconds: -1}
+		end       int
+	)
+	for _, match := range matches {
+		if !durationSeparator.MatchString(value[end:match[0]]) {
+			return 0, "", false
+		}
+		end = match[1]
+
+		amount, err := strconv.ParseFloat(value[match[2]:match[3]], 64)
+		if err != nil {
+			return 0, "", false
+		}
+		unit, ok := durationUnits[value[match[4]:match[5]]]
+		if !ok {
+			return 0, "", false
+		}
+
+		total += amount * unit.seconds
+		if precision.seconds < 0 || unit.seconds < precision.seconds {
+			precision = unit
+		}
+	}
+	if !durationSeparator.MatchString(value[end:]) {
+		return 0, "", false
+	}
+
+	return total, precision.precision, true
+}
@@ -0,0 +1,56 @@
+package collector
+
+import "testing"
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value         string
+		wantSeconds   float64
+		wantPrecision string
+		wantOK        bool
+	}{
+		{value: "3", wantSeconds: 3, wantPrecision: precisionSecond, wantOK: true},
+		{value: "2.5", wantSeconds: 2.5, wantPrecision: precisionSecond, wantOK: true},
+		{value: "16 days", wantSeconds: 16 * 86400, wantPrecision: precisionDay, wantOK: true},
+		{value: "1 day", wantSeconds: 86400, wantPrecision: precisionDay, wantOK: true},
+		{value: " 14 Days ", wantSeconds: 14 * 86400, wantPrecision: precisionDay, wantOK: true},
+		{value: "3 hours", wantSeconds: 3 * 3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "1 hour", wantSeconds: 3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "45 minutes", wantSeconds: 45 * 60, wantPrecision: precisionMinute, wantOK: true},
+		{value: "12 seconds", wantSeconds: 12, wantPrecision: precisionSecond, wantOK: true},
+		{value: "2 weeks", wantSeconds: 2 * 604800, wantPrecision: precisionWeek, wantOK: true},
+		{value: "1 day 3 hours", wantSeconds: 86400 + 3*3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "1 day, 3 hours", wantSeconds: 86400 + 3*3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "2 hours and 5 minutes", wantSeconds: 2*3600 + 5*60, wantPrecision: precisionMinute, wantOK: true},
+		{value: "5 minutes 1 day", wantSeconds: 86400 + 5*60, wantPrecision: precisionMinute, wantOK: true},
+		{value: "2d 4h", wantSeconds: 2*86400 + 4*3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "2d4h30m", wantSeconds: 2*86400 + 4*3600 + 30*60, wantPrecision: precisionMinute, wantOK: true},
+		{value: "1h 2m 3s", wantSeconds: 3723, wantPrecision: precisionSecond, wantOK: true},
+		{value: "10 sec", wantSeconds: 10, wantPrecision: precisionSecond, wantOK: true},
+		{value: "10 min.", wantSeconds: 600, wantPrecision: precisionMinute, wantOK: true},
+		{value: "3 hrs", wantSeconds: 3 * 3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "1.5 hours", wantSeconds: 5400, wantPrecision: precisionHour, wantOK: true},
+		{value: "16 дней", wantSeconds: 16 * 86400, wantPrecision: precisionDay, wantOK: true},
+		{value: "1 день 3 часа", wantSeconds: 86400 + 3*3600, wantPrecision: precisionHour, wantOK: true},
+		{value: "2 ч и 5 мин", wantSeconds: 2*3600 + 5*60, wantPrecision: precisionMinute, wantOK: true},
+		{value: "16天", wantSeconds: 16 * 86400, wantPrecision: precisionDay, wantOK: true},
+		{value: "3小时20分钟", wantSeconds: 3*3600 + 20*60, wantPrecision: precisionMinute, wantOK: true},
+		{value: ""},
+		{value: "days"},
+		{value: "abc days"},
+		{value: "16 fortnights"},
+		{value: "16 days ago"},
+		{value: "1 day or 2 hours"},
+		{value: "-3 hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			seconds, precision, ok := parseDuration(tt.value)
+			if seconds != tt.wantSeconds || precision != tt.wantPrecision || ok != tt.wantOK {
+				t.Errorf("parseDuration(%q) = %v, %q, %v, want %v, %q, %v",
+					tt.value, seconds, precision, ok, tt.wantSeconds, tt.wantPrecision, tt.wantOK)
+			}
+		})
+	}
+}
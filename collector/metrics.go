@@ -1,10 +1,137 @@
 package collector
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type MetricDef struct {
 	desc     *prometheus.Desc
 	getValue func(*LiteServerMetrics) (float64, []string)
+	// getValues is used instead of getValue for metrics exporting several series.
+	getValues func(*LiteServerMetrics) []metricValue
+	// getValuesAt is used instead of getValue for metrics derived from the time of the scrape.
+	getValuesAt func(*LiteServerMetrics, time.Time) []metricValue
+	// field is the LiteServerMetrics.ParsedFields key the metric is exported from.
+	// The metric is omitted unless the field was present and parsed.
+	field string
+	// valueType defaults to prometheus.GaugeValue.
+	valueType prometheus.ValueType
+	// stateSet is used instead of getValue for metrics exporting every known state.
+	stateSet *stateSet
+}
+
+// stateSet describes a metric exporting every known state with the value 1 for
+// the current state and 0 for the others, so a state change does not make the
+// series of the previous state disappear.
+type stateSet struct {
+	name   string
+	label  string
+	states []string
+	// getState returns the current state, false omits the metric. A state that
+	// is not known is exported as stateUnknown when states contains it.
+	getState func(*LiteServerMetrics, time.Time) (string, bool)
+	// getStates is used instead of getState by state sets with series for many
+	// instances, such as accounts, told apart by labels before label.
+	getStates func(*LiteServerMetrics, time.Time) []labeledState
+}
+
+// labeledState is the current state of one instance of a state set.
+type labeledState struct {
+	labels []string
+	state  string
+}
+
+// stateUnknown is the state of a state set whose current state is missing or not known.
+const stateUnknown = "unknown"
+
+// Known states of the state set metrics.
+var (
+	electionStates = []string{"open", "closed", stateUnknown}
+	serviceStates  = []string{"working", "not working", stateUnknown}
+)
+
+// newStateSet returns the definition of a state set metric with the states in label.
+func newStateSet(
+	name, help, label string, states []string, getState func(*LiteServerMetrics, time.Time) (string, bool),
+) MetricDef {
+	fqName := prometheus.BuildFQName(MetricNamespace, MetricSubsystem, name)
+	return MetricDef{
+		desc: prometheus.NewDesc(fqName, help, []string{label}, nil),
+		stateSet: &stateSet{
+			name:     fqName,
+			label:    label,
+			states:   states,
+			getState: getState,
+		},
+	}
+}
+
+// newLabeledStateSet returns the definition of a state set metric with a
+// series per instance, labeled with labels and the states in label.
+func newLabeledStateSet(
+	name, help string, labels []string, label string, states []string,
+	getStates func(*LiteServerMetrics, time.Time) []labeledState,
+) MetricDef {
+	fqName := prometheus.BuildFQName(MetricNamespace, MetricSubsystem, name)
+	return MetricDef{
+		desc: prometheus.NewDesc(fqName, help, append(slices.Clone(labels), label), nil),
+		stateSet: &stateSet{
+			name:      fqName,
+			label:     label,
+			states:    states,
+			getStates: getStates,
+		},
+	}
+}
+
+// values returns the series of every state as of now.
+func (s *stateSet) values(m *LiteServerMetrics, now time.Time) []metricValue {
+	if s.getStates != nil {
+		var values []metricValue
+		for _, instance := range s.getStates(m, now) {
+			values = append(values, s.stateValues(instance.labels, instance.state)...)
+		}
+		return values
+	}
+
+	current, ok := s.getState(m, now)
+	if !ok {
+		return nil
+	}
+	return s.stateValues(nil, current)
+}
+
+// stateValues returns the series of every state of one instance.
+func (s *stateSet) stateValues(labels []string, current string) []metricValue {
+	if !slices.Contains(s.states, current) && slices.Contains(s.states, stateUnknown) {
+		current = stateUnknown
+	}
+
+	values := make([]metricValue, 0, len(s.states))
+	for _, state := range s.states {
+		values = append(values, metricValue{value: boolValue(state == current), labels: append(slices.Clone(labels), state)})
+	}
+	return values
+}
+
+// StateSetLabel reports whether the metric family with the given full name is a
+// state set and returns the name of the label holding its states.
+func StateSetLabel(name string) (string, bool) {
+	for _, mDef := range Metrics {
+		if mDef.stateSet != nil && mDef.stateSet.name == name {
+			return mDef.stateSet.label, true
+		}
+	}
+	return "", false
+}
+
+type metricValue struct {
+	value  float64
+	labels []string
 }
 
 var Metrics = []MetricDef{
@@ -15,6 +142,7 @@ var Metrics = []MetricDef{
 			"Number of online validators",
 			nil, nil,
 		),
+		field: "online_validators",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.OnlineValidators, nil
 		},
@@ -25,6 +153,7 @@ var Metrics = []MetricDef{
 			"Total number of validators",
 			nil, nil,
 		),
+		field: "all_validators",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.AllValidators, nil
 		},
@@ -35,6 +164,7 @@ var Metrics = []MetricDef{
 			"Number of shardchains",
 			nil, nil,
 		),
+		field: "number_of_shardchains",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.NumberOfShardchains, nil
 		},
@@ -45,6 +175,7 @@ var Metrics = []MetricDef{
 			"Number of new offers",
 			nil, nil,
 		),
+		field: "new_offers",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.NewOffers, nil
 		},
@@ -55,6 +186,7 @@ var Metrics = []MetricDef{
 			"Total number of offers",
 			nil, nil,
 		),
+		field: "all_offers",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.AllOffers, nil
 		},
@@ -65,6 +197,7 @@ var Metrics = []MetricDef{
 			"Number of new complaints",
 			nil, nil,
 		),
+		field: "new_complaints",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.NewComplaints, nil
 		},
@@ -75,21 +208,25 @@ var Metrics = []MetricDef{
 			"Total number of complaints",
 			nil, nil,
 		),
+		field: "all_complaints",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.AllComplaints, nil
 		},
 	},
+	newStateSet("election_status", "Election status (open/closed/unknown)", "status", electionStates,
+		func(m *LiteServerMetrics, _ time.Time) (string, bool) {
+			return m.ElectionStatus, true
+		},
+	),
 	{
 		desc: prometheus.NewDesc(
-			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "election_status"),
-			"Election status (open/closed)",
-			[]string{"status"}, nil,
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "active_election_id"),
+			"ID of the active election, 0 when elections are closed",
+			nil, nil,
 		),
+		field: "active_election_id",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.ElectionStatus != "" {
-				return 1, []string{m.ElectionStatus}
-			}
-			return 0, []string{"unknown"}
+			return m.ActiveElectionID, nil
 		},
 	},
 
@@ -100,6 +237,7 @@ var Metrics = []MetricDef{
 			"Index of the local validator",
 			nil, nil,
 		),
+		field: "validator_index",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.ValidatorIndex, nil
 		},
@@ -110,11 +248,20 @@ var Metrics = []MetricDef{
 			"ADNL address of the local validator",
 			[]string{"address"}, nil,
 		),
+		field: "adnl_address",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.AdnlAddress != "" {
-				return 1, []string{m.AdnlAddress}
-			}
-			return 0, []string{"unknown"}
+			return 1, []string{m.AdnlAddress}
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "public_adnl_address"),
+			"Public ADNL address of the node",
+			[]string{"address"}, nil,
+		),
+		field: "public_adnl_address",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return 1, []string{m.PublicAdnlAddress}
 		},
 	},
 	{
@@ -123,11 +270,9 @@ var Metrics = []MetricDef{
 			"Local validator wallet address",
 			[]string{"address"}, nil,
 		),
+		field: "wallet_address",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.WalletAddress != "" {
-				return 1, []string{m.WalletAddress}
-			}
-			return 0, []string{"unknown"}
+			return 1, []string{m.WalletAddress}
 		},
 	},
 	{
@@ -136,64 +281,63 @@ var Metrics = []MetricDef{
 			"Balance of the local validator wallet",
 			nil, nil,
 		),
+		field: "wallet_balance",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.WalletBalance, nil
 		},
 	},
 	{
 		desc: prometheus.NewDesc(
-			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "mytoncore_status"),
-			"Status of Mytoncore",
-			[]string{"status"}, nil,
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_wallet_balance_nanotons"),
+			"Balance of the local validator wallet in nanotons",
+			nil, nil,
 		),
+		field: "local_validator_wallet_balance_nanotons",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.MytoncoreStatus != "" {
-				return 1, []string{m.MytoncoreStatus}
-			}
-			return 0, []string{"unknown"}
+			return float64(m.LocalValidatorWalletBalanceNanotons), nil
 		},
 	},
+	newStateSet("mytoncore_status", "Status of Mytoncore (working/not working/unknown)", "status", serviceStates,
+		func(m *LiteServerMetrics, _ time.Time) (string, bool) {
+			return m.MytoncoreStatus, true
+		},
+	),
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "mytoncore_uptime_seconds"),
-			"Uptime of Mytoncore in seconds",
-			nil, nil,
+			"Uptime of Mytoncore in seconds, precision is the smallest unit it was printed with",
+			[]string{"precision"}, nil,
 		),
+		field: "mytoncore_uptime_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			return m.MytoncoreUptimeSeconds, nil
+			return m.MytoncoreUptimeSeconds, []string{precisionLabel(m.MytoncoreUptimePrecision)}
 		},
 	},
-	{
-		desc: prometheus.NewDesc(
-			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_status"),
-			"Status of Local Validator",
-			[]string{"status"}, nil,
-		),
-		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.LocalValidatorStatus != "" {
-				return 1, []string{m.LocalValidatorStatus}
-			}
-			return 0, []string{"unknown"}
+	newStateSet("local_validator_status", "Status of Local Validator (working/not working/unknown)", "status", serviceStates,
+		func(m *LiteServerMetrics, _ time.Time) (string, bool) {
+			return m.LocalValidatorStatus, true
 		},
-	},
+	),
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_uptime_seconds"),
-			"Uptime of Local Validator in seconds",
-			nil, nil,
+			"Uptime of Local Validator in seconds, precision is the smallest unit it was printed with",
+			[]string{"precision"}, nil,
 		),
+		field: "local_validator_uptime_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			return m.LocalValidatorUptimeSeconds, nil
+			return m.LocalValidatorUptimeSeconds, []string{precisionLabel(m.LocalValidatorUptimePrecision)}
 		},
 	},
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_out_of_sync_seconds"),
-			"Local validator out of sync in seconds",
-			nil, nil,
+			"Local validator out of sync in seconds, precision is the smallest unit it was printed with",
+			[]string{"precision"}, nil,
 		),
+		field: "local_validator_out_of_sync_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			return m.LocalValidatorOutOfSyncSeconds, nil
+			return m.LocalValidatorOutOfSyncSeconds, []string{precisionLabel(m.LocalValidatorOutOfSyncPrecision)}
 		},
 	},
 	{
@@ -202,6 +346,7 @@ var Metrics = []MetricDef{
 			"Number of blocks since last state serialization",
 			nil, nil,
 		),
+		field: "local_validator_last_state_serialization_blocks",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.LocalValidatorLastStateSerializationBlocks, nil
 		},
@@ -209,24 +354,45 @@ var Metrics = []MetricDef{
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_database_size_gb"),
-			"Local validator database size in GB",
+			"Local validator database size in GB, deprecated by local_validator_database_size_bytes",
 			nil, nil,
 		),
+		field: "local_validator_database_size_gb",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.LocalValidatorDatabaseSizeGB, nil
 		},
 	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_database_size_bytes"),
+			"Local validator database size in bytes",
+			nil, nil,
+		),
+		field: "local_validator_database_size_bytes",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.LocalValidatorDatabaseSizeBytes, nil
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_database_disk_usage_ratio"),
+			"Used part of the disk holding the local validator database, from 0 to 1",
+			nil, nil,
+		),
+		field: "local_validator_database_disk_usage_ratio",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.LocalValidatorDatabaseDiskUsageRatio, nil
+		},
+	},
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "version_mytonctrl"),
 			"Version of MyTonCtrl",
 			[]string{"version"}, nil,
 		),
+		field: "version_mytonctrl",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.VersionMytonctrl != "" {
-				return 1, []string{m.VersionMytonctrl}
-			}
-			return 0, []string{"unknown"}
+			return 1, []string{m.VersionMytonctrl}
 		},
 	},
 	{
@@ -235,11 +401,440 @@ var Metrics = []MetricDef{
 			"Version of Validator",
 			[]string{"version"}, nil,
 		),
+		field: "version_validator",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return 1, []string{m.VersionValidator}
+		},
+	},
+
+	// Local Validator Election Participation Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_election_participant"),
+			"Whether the local validator wallet has a stake in the current election",
+			nil, nil,
+		),
+		field: "local_validator_stake_tons",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			if m.LocalValidatorStakeTONs > 0 {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "local_validator_stake_tons"),
+			"Stake of the local validator in the current election in TONs",
+			nil, nil,
+		),
+		field: "local_validator_stake_tons",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.LocalValidatorStakeTONs, nil
+		},
+	},
+	// The stake state is known once the election entries pass saw a stake,
+	// it is exported as unknown whenever the pass ran before that.
+	newStateSet("local_validator_stake_state",
+		"State of the local validator stake (frozen/returned/unknown)",
+		"state", stakeStates,
+		func(m *LiteServerMetrics, _ time.Time) (string, bool) {
+			return m.LocalValidatorStakeState, m.Parsed("local_validator_stake_tons")
+		},
+	),
+
+	// Local Validator Efficiency Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_efficiency_ratio"),
+			"Efficiency of the local validator in the current round, from 0 to 1",
+			nil, nil,
+		),
+		field: "validator_efficiency_ratio",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.ValidatorEfficiencyRatio, nil
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "blocks_created_total"),
+			"Number of blocks created by the local validator in the current round",
+			[]string{"chain"}, nil,
+		),
+		field:     "blocks_created",
+		valueType: prometheus.CounterValue,
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return chainValues(m.MasterBlocksCreated, m.WorkBlocksCreated)
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "blocks_expected_total"),
+			"Number of blocks the local validator was expected to create in the current round",
+			[]string{"chain"}, nil,
+		),
+		field:     "blocks_expected",
+		valueType: prometheus.CounterValue,
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return chainValues(m.MasterBlocksExpected, m.WorkBlocksExpected)
+		},
+	},
+
+	// Validator Set Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_online"),
+			"Whether the validator of the current set is online",
+			[]string{"adnl", "pubkey"}, nil,
+		),
+		field: "validators",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Validators))
+			for _, v := range m.Validators {
+				values = append(values, metricValue{value: boolValue(v.Online), labels: []string{v.AdnlAddress, v.PublicKey}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_weight"),
+			"Weight of the validator in the current set",
+			[]string{"adnl", "pubkey"}, nil,
+		),
+		field: "validators",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Validators))
+			for _, v := range m.Validators {
+				values = append(values, metricValue{value: v.Weight, labels: []string{v.AdnlAddress, v.PublicKey}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_stake_tons"),
+			"Stake of the validator in the current set in TONs",
+			[]string{"adnl", "pubkey"}, nil,
+		),
+		field: "validators",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Validators))
+			for _, v := range m.Validators {
+				values = append(values, metricValue{value: v.StakeTONs, labels: []string{v.AdnlAddress, v.PublicKey}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_efficiency_ratio"),
+			"Efficiency of the validator in the current round, from 0 to 1",
+			[]string{"adnl", "pubkey"}, nil,
+		),
+		field: "validators",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Validators))
+			for _, v := range m.Validators {
+				if v.EfficiencyRatio != nil {
+					values = append(values, metricValue{value: *v.EfficiencyRatio, labels: []string{v.AdnlAddress, v.PublicKey}})
+				}
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_wallet"),
+			"Wallet address of the validator in the current set",
+			[]string{"adnl", "pubkey", "address"}, nil,
+		),
+		field: "validators",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Validators))
+			for _, v := range m.Validators {
+				values = append(values, metricValue{value: 1, labels: []string{v.AdnlAddress, v.PublicKey, v.WalletAddress}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "validator_set_dropped_validators"),
+			"Number of validators of the current set left out by the validator set limit",
+			nil, nil,
+		),
+		field: "validators",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.ValidatorSetDropped, nil
+		},
+	},
+
+	// Offers and Complaints Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "offer_config_param"),
+			"ID of the config param changed by the open offer",
+			[]string{"hash", "param"}, nil,
+		),
+		field: "offers",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Offers))
+			for _, offer := range m.Offers {
+				values = append(values, metricValue{value: offer.ConfigParamID, labels: offerLabels(offer)})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "offer_votes"),
+			"Number of validators that voted for the open offer",
+			[]string{"hash", "param"}, nil,
+		),
+		field: "offers",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Offers))
+			for _, offer := range m.Offers {
+				values = append(values, metricValue{value: offer.Votes, labels: offerLabels(offer)})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "offer_approved_percent"),
+			"Approval of the open offer in percent of the validator weight",
+			[]string{"hash", "param"}, nil,
+		),
+		field: "offers",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Offers))
+			for _, offer := range m.Offers {
+				values = append(values, metricValue{value: offer.ApprovedPercent, labels: offerLabels(offer)})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "complaint_fine_tons"),
+			"Fine suggested by the complaint in TONs",
+			[]string{"hash", "election_id", "adnl"}, nil,
+		),
+		field: "complaints",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Complaints))
+			for _, complaint := range m.Complaints {
+				values = append(values, metricValue{value: complaint.FineTONs, labels: complaintLabels(complaint)})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "complaint_targets_local_validator"),
+			"Whether the complaint targets the local validator",
+			[]string{"hash", "election_id", "adnl"}, nil,
+		),
+		field: "complaints",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Complaints))
+			for _, complaint := range m.Complaints {
+				values = append(values, metricValue{value: boolValue(complaint.TargetsLocal), labels: complaintLabels(complaint)})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "complaints_against_local_validator"),
+			"Number of complaints against the local validator",
+			nil, nil,
+		),
+		field: "complaints_against_local_validator",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.VersionValidator != "" {
-				return 1, []string{m.VersionValidator}
+			return m.ComplaintsAgainstLocalValidator, nil
+		},
+	},
+
+	// Watched Accounts Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "account_balance"),
+			"Balance of a watched account in TONs",
+			[]string{"address", "alias"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.Accounts))
+			for _, account := range m.Accounts {
+				values = append(values, metricValue{
+					value:  float64(account.BalanceNanotons) / nanotonsPerTON,
+					labels: []string{account.Address, account.Alias},
+				})
 			}
-			return 0, []string{"unknown"}
+			return values
+		},
+	},
+	newLabeledStateSet("account_status", "State of a watched account (active/uninit/frozen/empty/unknown)",
+		[]string{"address"}, "state", accountStates,
+		func(m *LiteServerMetrics, _ time.Time) []labeledState {
+			states := make([]labeledState, 0, len(m.Accounts))
+			for _, account := range m.Accounts {
+				states = append(states, labeledState{labels: []string{account.Address}, state: account.State})
+			}
+			return states
+		}),
+
+	// Nominator Pools Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "pool_balance_tons"),
+			"Balance of a nominator pool in TONs",
+			[]string{"address"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return poolValues(m, func(pool Pool) *float64 { return &pool.BalanceTONs })
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "pool_nominators"),
+			"Number of nominators of a nominator pool",
+			[]string{"address"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return poolValues(m, func(pool Pool) *float64 { return pool.Nominators })
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "pool_validator_reward_share_ratio"),
+			"Share of the nominator pool rewards going to the validator, from 0 to 1",
+			[]string{"address"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return poolValues(m, func(pool Pool) *float64 { return pool.ValidatorRewardShareRatio })
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "pool_pending_deposits_tons"),
+			"Deposits of the nominator pool waiting for the next stake in TONs",
+			[]string{"address"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return poolValues(m, func(pool Pool) *float64 { return pool.PendingDepositsTONs })
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "pool_pending_withdrawals"),
+			"Number of withdrawal requests of the nominator pool waiting for the stake to return",
+			[]string{"address"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return poolValues(m, func(pool Pool) *float64 { return pool.PendingWithdrawals })
+		},
+	},
+	newLabeledStateSet("pool_state", "State of a nominator pool (active/stopped/unknown)",
+		[]string{"address"}, "state", poolStates,
+		func(m *LiteServerMetrics, _ time.Time) []labeledState {
+			states := make([]labeledState, 0, len(m.Pools))
+			for _, pool := range m.Pools {
+				states = append(states, labeledState{labels: []string{pool.Address}, state: pool.State})
+			}
+			return states
+		}),
+
+	// Host Load Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "cpu_count"),
+			"Number of CPUs reported alongside the load average",
+			nil, nil,
+		),
+		field: "cpu_count",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.CPUCount, nil
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "load_average"),
+			"System load average",
+			[]string{"window"}, nil,
+		),
+		field: "load_average",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return []metricValue{
+				{value: m.LoadAverage1m, labels: []string{"1m"}},
+				{value: m.LoadAverage5m, labels: []string{"5m"}},
+				{value: m.LoadAverage15m, labels: []string{"15m"}},
+			}
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "network_load_average_mbits"),
+			"Network load average in Mbit/s",
+			[]string{"window"}, nil,
+		),
+		field: "network_load_average",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return []metricValue{
+				{value: m.NetworkLoadAverage1m, labels: []string{"1m"}},
+				{value: m.NetworkLoadAverage5m, labels: []string{"5m"}},
+				{value: m.NetworkLoadAverage15m, labels: []string{"15m"}},
+			}
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "memory_used_gb"),
+			"Used memory in GB",
+			[]string{"type"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return memoryValues(m, m.MemoryRAMUsedGB, m.MemorySwapUsedGB)
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "memory_usage_percent"),
+			"Used memory in percent",
+			[]string{"type"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			return memoryValues(m, m.MemoryRAMUsagePercent, m.MemorySwapUsagePercent)
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "disk_load_average_mbs"),
+			"Disk load average in MB/s",
+			[]string{"device"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.DisksLoad))
+			for _, disk := range m.DisksLoad {
+				values = append(values, metricValue{value: disk.ThroughputMBs, labels: []string{disk.Device}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "disk_utilization_percent"),
+			"Disk utilization in percent",
+			[]string{"device"}, nil,
+		),
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.DisksLoad))
+			for _, disk := range m.DisksLoad {
+				values = append(values, metricValue{value: disk.UtilizationPercent, labels: []string{disk.Device}})
+			}
+			return values
 		},
 	},
 
@@ -250,11 +845,9 @@ var Metrics = []MetricDef{
 			"Configurator address",
 			[]string{"address"}, nil,
 		),
+		field: "configurator_address",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.ConfiguratorAddress != "" {
-				return 1, []string{m.ConfiguratorAddress}
-			}
-			return 0, []string{"unknown"}
+			return 1, []string{m.ConfiguratorAddress}
 		},
 	},
 	{
@@ -263,11 +856,9 @@ var Metrics = []MetricDef{
 			"Elector address",
 			[]string{"address"}, nil,
 		),
+		field: "elector_address",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
-			if m.ElectorAddress != "" {
-				return 1, []string{m.ElectorAddress}
-			}
-			return 0, []string{"unknown"}
+			return 1, []string{m.ElectorAddress}
 		},
 	},
 	{
@@ -276,6 +867,7 @@ var Metrics = []MetricDef{
 			"Validation period in seconds",
 			nil, nil,
 		),
+		field: "validation_period_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.ValidationPeriodSeconds, nil
 		},
@@ -286,16 +878,40 @@ var Metrics = []MetricDef{
 			"Duration of elections in seconds",
 			nil, nil,
 		),
+		field: "duration_of_elections_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.DurationOfElectionsSeconds, nil
 		},
 	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "elections_start_before_seconds"),
+			"Seconds before the start of a validation cycle its elections start",
+			nil, nil,
+		),
+		field: "elections_start_before_seconds",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.ElectionsStartBeforeSeconds, nil
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "elections_end_before_seconds"),
+			"Seconds before the start of a validation cycle its elections end",
+			nil, nil,
+		),
+		field: "elections_end_before_seconds",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.ElectionsEndBeforeSeconds, nil
+		},
+	},
 	{
 		desc: prometheus.NewDesc(
 			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "hold_period_seconds"),
 			"Hold period in seconds",
 			nil, nil,
 		),
+		field: "hold_period_seconds",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.HoldPeriodSeconds, nil
 		},
@@ -306,6 +922,7 @@ var Metrics = []MetricDef{
 			"Minimum stake in TONs",
 			nil, nil,
 		),
+		field: "minimum_stake_tons",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.MinimumStakeTONs, nil
 		},
@@ -316,6 +933,7 @@ var Metrics = []MetricDef{
 			"Maximum stake in TONs",
 			nil, nil,
 		),
+		field: "maximum_stake_tons",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.MaximumStakeTONs, nil
 		},
@@ -328,6 +946,7 @@ var Metrics = []MetricDef{
 			"TON network launch timestamp",
 			nil, nil,
 		),
+		field: "network_launched_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.NetworkLaunchedTimestamp, nil
 		},
@@ -338,6 +957,7 @@ var Metrics = []MetricDef{
 			"Start of the validation cycle timestamp",
 			nil, nil,
 		),
+		field: "start_validation_cycle_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.StartValidationCycleTimestamp, nil
 		},
@@ -348,6 +968,7 @@ var Metrics = []MetricDef{
 			"End of the validation cycle timestamp",
 			nil, nil,
 		),
+		field: "end_validation_cycle_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.EndValidationCycleTimestamp, nil
 		},
@@ -358,6 +979,7 @@ var Metrics = []MetricDef{
 			"Start of elections timestamp",
 			nil, nil,
 		),
+		field: "start_elections_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.StartElectionsTimestamp, nil
 		},
@@ -368,6 +990,7 @@ var Metrics = []MetricDef{
 			"End of elections timestamp",
 			nil, nil,
 		),
+		field: "end_elections_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.EndElectionsTimestamp, nil
 		},
@@ -378,8 +1001,138 @@ var Metrics = []MetricDef{
 			"Beginning of the next elections timestamp",
 			nil, nil,
 		),
+		field: "begin_next_elections_timestamp",
 		getValue: func(m *LiteServerMetrics) (float64, []string) {
 			return m.BeginNextElectionsTimestamp, nil
 		},
 	},
+
+	// Election Cycle Metrics, derived from the timestamps at the time of the scrape
+	newStateSet("cycle_phase",
+		"Phase of the election and validation cycle (elections_open/elections_closed_waiting/validating/hold)",
+		"phase", cyclePhases, cyclePhase,
+	),
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "seconds_until_next_elections"),
+			"Seconds until the beginning of the next elections, 0 once they began",
+			nil, nil,
+		),
+		field: "begin_next_elections_timestamp",
+		getValuesAt: func(m *LiteServerMetrics, now time.Time) []metricValue {
+			return []metricValue{{value: secondsUntil(m.BeginNextElectionsTimestamp, now)}}
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "seconds_until_cycle_end"),
+			"Seconds until the end of the validation cycle, 0 once it ended",
+			nil, nil,
+		),
+		field: "end_validation_cycle_timestamp",
+		getValuesAt: func(m *LiteServerMetrics, now time.Time) []metricValue {
+			return []metricValue{{value: secondsUntil(m.EndValidationCycleTimestamp, now)}}
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "cycle_progress_ratio"),
+			"Elapsed part of the validation cycle, from 0 to 1",
+			nil, nil,
+		),
+		getValuesAt: func(m *LiteServerMetrics, now time.Time) []metricValue {
+			progress, ok := cycleProgress(m, now)
+			if !ok {
+				return nil
+			}
+			return []metricValue{{value: progress}}
+		},
+	},
+
+	// Mytoncore Database Metrics
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "past_stake_tons"),
+			"Stake of the local validator in past elections in TONs",
+			[]string{"election_id"}, nil,
+		),
+		field: "past_stakes",
+		getValues: func(m *LiteServerMetrics) []metricValue {
+			values := make([]metricValue, 0, len(m.PastStakes))
+			for _, stake := range m.PastStakes {
+				values = append(values, metricValue{value: stake.StakeTONs, labels: []string{stake.ElectionID}})
+			}
+			return values
+		},
+	},
+	{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricNamespace, MetricSubsystem, "latest_participated_election_id"),
+			"ID of the latest election the local validator participated in",
+			nil, nil,
+		),
+		field: "latest_participated_election_id",
+		getValue: func(m *LiteServerMetrics) (float64, []string) {
+			return m.LatestParticipatedElectionID, nil
+		},
+	},
+}
+
+// offerLabels returns the hash and config param labels of an offer.
+func offerLabels(offer Offer) []string {
+	return []string{offer.Hash, strconv.FormatFloat(offer.ConfigParamID, 'f', -1, 64)}
+}
+
+// complaintLabels returns the hash, election id and target ADNL labels of a complaint.
+func complaintLabels(complaint Complaint) []string {
+	return []string{complaint.Hash, complaint.ElectionID, complaint.TargetAdnl}
+}
+
+// poolValues returns a value of every pool labeled with its address, skipping
+// the pools without the value.
+func poolValues(m *LiteServerMetrics, value func(Pool) *float64) []metricValue {
+	values := make([]metricValue, 0, len(m.Pools))
+	for _, pool := range m.Pools {
+		if v := value(pool); v != nil {
+			values = append(values, metricValue{value: *v, labels: []string{pool.Address}})
+		}
+	}
+	return values
+}
+
+// precisionLabel returns the precision label of a duration, values without a
+// precision, such as the ones from JSON output, are exact to the second.
+func precisionLabel(precision string) string {
+	if precision == "" {
+		return precisionSecond
+	}
+	return precision
+}
+
+// boolValue converts a flag to a gauge value.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// chainValues returns the masterchain and workchain series of a blocks metric.
+func chainValues(master, work float64) []metricValue {
+	return []metricValue{
+		{value: master, labels: []string{"master"}},
+		{value: work, labels: []string{"work"}},
+	}
+}
+
+// memoryValues returns the ram and swap series of a memory metric, omitting the unparsed ones.
+func memoryValues(m *LiteServerMetrics, ram, swap float64) []metricValue {
+	var values []metricValue
+	if m.Parsed("memory_ram") {
+		values = append(values, metricValue{value: ram, labels: []string{"ram"}})
+	}
+	if m.Parsed("memory_swap") {
+		values = append(values, metricValue{value: swap, labels: []string{"swap"}})
+	}
+	return values
 }
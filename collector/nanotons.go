@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// nanotonsPerTON is the number of nanotons in one TON.
+const nanotonsPerTON = 1_000_000_000
+
+// Nanotons is an exact amount of TON in nanotons, the smallest unit of TON.
+// It is encoded in JSON as a decimal string, so consumers reading numbers as
+// float64 do not lose precision on large amounts.
+type Nanotons int64
+
+// MarshalJSON encodes the amount as a decimal string.
+func (n Nanotons) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(int64(n), 10))), nil
+}
+
+// UnmarshalJSON decodes the amount from a decimal string or an integer.
+func (n *Nanotons) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if strings.HasPrefix(value, `"`) {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid nanotons %q: %w", value, err)
+	}
+	*n = Nanotons(parsed)
+
+	return nil
+}
+
+// parseNanotons parses an amount of TON such as "95290.938201014" or
+// "95290.938201014 TON" exactly, without going through float64.
+func parseNanotons(value string) (Nanotons, bool) {
+	// Handle cases like "123.45 TON" by taking the first field
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0, false
+	}
+
+	whole, frac, _ := strings.Cut(fields[0], ".")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, false
+	}
+	// Digits below a nanoton can only be trailing zeros.
+	if len(frac) > 9 {
+		if strings.Trim(frac[9:], "0") != "" {
+			return 0, false
+		}
+		frac = frac[:9]
+	}
+
+	tons, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	nanotons, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if tons > (math.MaxInt64-nanotons)/nanotonsPerTON {
+		return 0, false
+	}
+
+	return Nanotons(tons*nanotonsPerTON + nanotons), true
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
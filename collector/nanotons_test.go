@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseNanotons(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   Nanotons
+		wantOK bool
+	}{
+		{value: "95290.938201014", want: 95290938201014, wantOK: true},
+		{value: "95290.938201014 TON", want: 95290938201014, wantOK: true},
+		{value: "4999999999.999999999", want: 4999999999999999999, wantOK: true},
+		{value: "10000", want: 10000000000000, wantOK: true},
+		{value: "0.5", want: 500000000, wantOK: true},
+		{value: "1.000000001", want: 1000000001, wantOK: true},
+		{value: "1.0000000010", want: 1000000001, wantOK: true},
+		{value: "0", want: 0, wantOK: true},
+		{value: ""},
+		{value: "lots"},
+		{value: ".5"},
+		{value: "-1.5"},
+		{value: "+1.5"},
+		{value: "1e9"},
+		{value: "1.0000000001"},
+		{value: "9223372036.854775808"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseNanotons(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseNanotons(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNanotons_JSON(t *testing.T) {
+	data, err := json.Marshal(Nanotons(4999999999999999999))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"4999999999999999999"` {
+		t.Errorf("json.Marshal() = %s, want a string", data)
+	}
+
+	for _, input := range []string{`"4999999999999999999"`, `4999999999999999999`} {
+		var got Nanotons
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Errorf("json.Unmarshal(%s) error = %v", input, err)
+		}
+		if got != 4999999999999999999 {
+			t.Errorf("json.Unmarshal(%s) = %v, want 4999999999999999999", input, got)
+		}
+	}
+
+	var got Nanotons
+	if err := json.Unmarshal([]byte(`"lots"`), &got); err == nil {
+		t.Error("json.Unmarshal() of an invalid amount succeeded")
+	}
+}
@@ -2,14 +2,16 @@ package collector
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/commander-cli/cmd"
 )
 
 // LiteServerMetrics holds the parsed metrics from MyTonCtrl output.
@@ -24,30 +26,85 @@ type LiteServerMetrics struct {
 	NewComplaints       float64 `json:"new_complaints"`
 	AllComplaints       float64 `json:"all_complaints"`
 	ElectionStatus      string  `json:"election_status"`
+	ActiveElectionID    float64 `json:"active_election_id"`
 
 	// Local Validator Status Metrics
-	ValidatorIndex                             float64 `json:"validator_index"`
-	AdnlAddress                                string  `json:"adnl_address"`
-	WalletAddress                              string  `json:"wallet_address"`
-	WalletBalance                              float64 `json:"wallet_balance"`
-	MytoncoreStatus                            string  `json:"mytoncore_status"`
-	MytoncoreUptimeSeconds                     float64 `json:"mytoncore_uptime_seconds"`
-	LocalValidatorStatus                       string  `json:"local_validator_status"`
-	LocalValidatorUptimeSeconds                float64 `json:"local_validator_uptime_seconds"`
-	LocalValidatorOutOfSyncSeconds             float64 `json:"local_validator_out_of_sync_seconds"`
-	LocalValidatorLastStateSerializationBlocks float64 `json:"local_validator_last_state_serialization_blocks"`
-	LocalValidatorDatabaseSizeGB               float64 `json:"local_validator_database_size_gb"`
-	VersionMytonctrl                           string  `json:"version_mytonctrl"`
-	VersionValidator                           string  `json:"version_validator"`
+	ValidatorIndex    float64 `json:"validator_index"`
+	AdnlAddress       string  `json:"adnl_address"`
+	PublicAdnlAddress string  `json:"public_adnl_address"`
+	WalletAddress     string  `json:"wallet_address"`
+	WalletBalance     float64 `json:"wallet_balance"`
+	// LocalValidatorWalletBalanceNanotons is the exact wallet balance, encoded as a string in JSON.
+	LocalValidatorWalletBalanceNanotons        Nanotons `json:"local_validator_wallet_balance_nanotons"`
+	MytoncoreStatus                            string   `json:"mytoncore_status"`
+	MytoncoreUptimeSeconds                     float64  `json:"mytoncore_uptime_seconds"`
+	LocalValidatorStatus                       string   `json:"local_validator_status"`
+	LocalValidatorUptimeSeconds                float64  `json:"local_validator_uptime_seconds"`
+	LocalValidatorOutOfSyncSeconds             float64  `json:"local_validator_out_of_sync_seconds"`
+	LocalValidatorLastStateSerializationBlocks float64  `json:"local_validator_last_state_serialization_blocks"`
+	// Precisions are the smallest units the durations above were printed with, such as "day".
+	MytoncoreUptimePrecision         string  `json:"mytoncore_uptime_precision"`
+	LocalValidatorUptimePrecision    string  `json:"local_validator_uptime_precision"`
+	LocalValidatorOutOfSyncPrecision string  `json:"local_validator_out_of_sync_precision"`
+	LocalValidatorDatabaseSizeGB     float64 `json:"local_validator_database_size_gb"`
+	LocalValidatorDatabaseSizeBytes  float64 `json:"local_validator_database_size_bytes"`
+	// LocalValidatorDatabaseDiskUsageRatio is the used part of the disk holding the database, from 0 to 1.
+	LocalValidatorDatabaseDiskUsageRatio float64 `json:"local_validator_database_disk_usage_ratio"`
+	VersionMytonctrl                     string  `json:"version_mytonctrl"`
+	VersionValidator                     string  `json:"version_validator"`
+
+	// Local Validator Election Participation Metrics, read by the election entries pass
+	LocalValidatorStakeTONs  float64 `json:"local_validator_stake_tons"`
+	LocalValidatorStakeState string  `json:"local_validator_stake_state"`
+
+	// Local Validator Efficiency Metrics, read by the validator list pass
+	ValidatorEfficiencyRatio float64 `json:"validator_efficiency_ratio"`
+	MasterBlocksCreated      float64 `json:"master_blocks_created"`
+	MasterBlocksExpected     float64 `json:"master_blocks_expected"`
+	WorkBlocksCreated        float64 `json:"work_blocks_created"`
+	WorkBlocksExpected       float64 `json:"work_blocks_expected"`
+
+	// Validator Set Metrics, read by the validator list pass
+	Validators          []Validator `json:"validators"`
+	ValidatorSetDropped float64     `json:"validator_set_dropped"`
+
+	// Offers and Complaints Metrics, read by the offers and complaints pass
+	Offers                          []Offer     `json:"offers"`
+	Complaints                      []Complaint `json:"complaints"`
+	ComplaintsAgainstLocalValidator float64     `json:"complaints_against_local_validator"`
+
+	// Watched Accounts Metrics, read by the accounts pass
+	Accounts []AccountStatus `json:"accounts"`
+
+	// Nominator Pools Metrics, read by the pools pass
+	Pools []Pool `json:"pools"`
+
+	// Host Load Metrics
+	CPUCount               float64    `json:"cpu_count"`
+	LoadAverage1m          float64    `json:"load_average_1m"`
+	LoadAverage5m          float64    `json:"load_average_5m"`
+	LoadAverage15m         float64    `json:"load_average_15m"`
+	NetworkLoadAverage1m   float64    `json:"network_load_average_1m"`
+	NetworkLoadAverage5m   float64    `json:"network_load_average_5m"`
+	NetworkLoadAverage15m  float64    `json:"network_load_average_15m"`
+	MemoryRAMUsedGB        float64    `json:"memory_ram_used_gb"`
+	MemoryRAMUsagePercent  float64    `json:"memory_ram_usage_percent"`
+	MemorySwapUsedGB       float64    `json:"memory_swap_used_gb"`
+	MemorySwapUsagePercent float64    `json:"memory_swap_usage_percent"`
+	DisksLoad              []DiskLoad `json:"disks_load"`
 
 	// TON Network Configuration Metrics
 	ConfiguratorAddress        string  `json:"configurator_address"`
 	ElectorAddress             string  `json:"elector_address"`
 	ValidationPeriodSeconds    float64 `json:"validation_period_seconds"`
 	DurationOfElectionsSeconds float64 `json:"duration_of_elections_seconds"`
-	HoldPeriodSeconds          float64 `json:"hold_period_seconds"`
-	MinimumStakeTONs           float64 `json:"minimum_stake_tons"`
-	MaximumStakeTONs           float64 `json:"maximum_stake_tons"`
+	// ElectionsStartBeforeSeconds and ElectionsEndBeforeSeconds are the pair printed
+	// as "Duration of elections": when elections start and end before the cycle starts.
+	ElectionsStartBeforeSeconds float64 `json:"elections_start_before_seconds"`
+	ElectionsEndBeforeSeconds   float64 `json:"elections_end_before_seconds"`
+	HoldPeriodSeconds           float64 `json:"hold_period_seconds"`
+	MinimumStakeTONs            float64 `json:"minimum_stake_tons"`
+	MaximumStakeTONs            float64 `json:"maximum_stake_tons"`
 
 	// TON Timestamps Metrics
 	NetworkLaunchedTimestamp      float64 `json:"network_launched_timestamp"`
@@ -56,55 +113,179 @@ type LiteServerMetrics struct {
 	StartElectionsTimestamp       float64 `json:"start_elections_timestamp"`
 	EndElectionsTimestamp         float64 `json:"end_elections_timestamp"`
 	BeginNextElectionsTimestamp   float64 `json:"begin_next_elections_timestamp"`
+
+	// Mytoncore Database Metrics, only read by the mytoncore db source
+	PastStakes                   []PastStake `json:"past_stakes"`
+	LatestParticipatedElectionID float64     `json:"latest_participated_election_id"`
+
+	// ParsedFields holds the fields found in the output and whether their value
+	// parsed. Keys are JSON field names, values parsed from one line together
+	// share a key such as "load_average" or "memory_ram". Missing fields have no entry.
+	ParsedFields map[string]bool `json:"parsed_fields"`
 }
 
-// Parser encapsulates the parsing logic for MyTonCtrl status output.
-type Parser struct{}
+// DiskLoad holds the load average of a single disk device.
+type DiskLoad struct {
+	Device             string  `json:"device"`
+	ThroughputMBs      float64 `json:"throughput_mbs"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
 
-// NewParser initializes and returns a new Parser instance.
-func NewParser() *Parser {
-	return &Parser{}
+// Validator holds a validator of the current validator set.
+type Validator struct {
+	AdnlAddress   string  `json:"adnl_address"`
+	PublicKey     string  `json:"public_key"`
+	WalletAddress string  `json:"wallet_address"`
+	Online        bool    `json:"online"`
+	Weight        float64 `json:"weight"`
+	StakeTONs     float64 `json:"stake_tons"`
+	// EfficiencyRatio is nil until mytoncore has computed it.
+	EfficiencyRatio *float64 `json:"efficiency_ratio,omitempty"`
 }
 
-// Parse executes the 'mytonctrl status' command and parses its output into LightServerMetrics.
-func (p *Parser) Parse() (*LiteServerMetrics, error) {
-	metrics, err := p.executeCommand()
-	if err != nil {
-		return nil, err
+// Offer holds an open config change offer.
+type Offer struct {
+	Hash            string  `json:"hash"`
+	ConfigParamID   float64 `json:"config_param_id"`
+	Votes           float64 `json:"votes"`
+	ApprovedPercent float64 `json:"approved_percent"`
+}
+
+// Complaint holds a complaint against a validator of the current round.
+type Complaint struct {
+	Hash         string  `json:"hash"`
+	ElectionID   string  `json:"election_id"`
+	TargetAdnl   string  `json:"target_adnl"`
+	FineTONs     float64 `json:"fine_tons"`
+	TargetsLocal bool    `json:"targets_local"`
+}
+
+// PastStake holds the stake the local validator made in an election.
+type PastStake struct {
+	ElectionID string  `json:"election_id"`
+	StakeTONs  float64 `json:"stake_tons"`
+}
+
+// limitPastStakes keeps the stakes of the latest limit elections, so the
+// election_id label does not grow with the history mytoncore keeps.
+func (m *LiteServerMetrics) limitPastStakes(limit int) {
+	if limit <= 0 || len(m.PastStakes) <= limit {
+		return
 	}
 
-	return metrics, nil
+	sort.Slice(m.PastStakes, func(i, j int) bool {
+		return electionIDLess(m.PastStakes[i].ElectionID, m.PastStakes[j].ElectionID)
+	})
+	m.PastStakes = m.PastStakes[len(m.PastStakes)-limit:]
 }
 
-var ansiEscape = regexp.MustCompile(`\x1B[@-_][0-?]*[ -/]*[@-~]`)
+// Errors returned by Parse, one per failure cause.
+var (
+	// ErrExec is returned when the 'mytonctrl status' output cannot be fetched.
+	ErrExec = errors.New("exec")
+	// ErrExitCode is returned when the 'mytonctrl status' command exits with a non-zero code.
+	ErrExitCode = errors.New("exit code")
+	// ErrParse is returned when the 'mytonctrl status' output cannot be parsed.
+	ErrParse = errors.New("parse")
+	// ErrUnavailable is returned when the container, user or process to run mytonctrl in is missing.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrTimeout is returned when the 'mytonctrl status' command does not finish in time.
+	ErrTimeout = errors.New("timeout")
+)
 
-// executeCommand runs the 'mytonctrl status' command and parses its output.
-func (p *Parser) executeCommand() (*LiteServerMetrics, error) {
-	command := cmd.NewCommand("echo 'status' | mytonctrl", cmd.WithInheritedEnvironment(nil))
+// Parser encapsulates the parsing logic for MyTonCtrl status output.
+type Parser struct {
+	source  Source
+	timeout time.Duration
+	options ParserOptions
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
 
-	if err := command.Execute(); err != nil {
+	// Latest 'vas' results of the watched accounts by address.
+	accountsMutex sync.Mutex
+	accounts      map[string]accountQuery
+
+	// Whether the mytonctrl build prints the JSON lists of the structured pass.
+	jsonMutex   sync.Mutex
+	jsonSupport int
+
+	// Latest election the local validator was seen with a stake in by the
+	// election entries pass.
+	electionsMutex   sync.Mutex
+	latestElectionID float64
+}
+
+// NewParser initializes and returns a new Parser instance reading from source.
+// The timeout applies to the status fetch and to every other mytonctrl command
+// on its own. A zero timeout means they run until the context is done.
+func NewParser(source Source, timeout time.Duration, options ParserOptions) *Parser {
+	return &Parser{source: source, timeout: timeout, options: options, now: time.Now}
+}
+
+// Parse fetches the 'mytonctrl status' output from the source and parses it into LightServerMetrics.
+func (p *Parser) Parse(ctx context.Context) (*LiteServerMetrics, error) {
+	output, err := p.fetch(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	if command.ExitCode() != 0 {
-		return nil, fmt.Errorf("command %q failed with exit code %d: %s", command.Command, command.ExitCode(), command.Combined())
+	metrics, err := p.ParseOutput(output)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing output: %w: %w", ErrParse, err)
 	}
+	metrics.limitPastStakes(p.options.PastStakesLimit)
 
-	metrics, err := p.ParseOutput(command.Stdout())
-	if err != nil {
-		return nil, fmt.Errorf("error parsing output: %w", err)
+	for _, pass := range []func(context.Context, *LiteServerMetrics) error{
+		p.runStructured,
+		p.runValidatorList,
+		p.runOffersComplaints,
+		p.runElectionEntries,
+		p.runAccounts,
+		p.runPools,
+	} {
+		if err := pass(ctx, &metrics); err != nil {
+			log.Printf("Error collecting optional metrics: %v", err)
+		}
 	}
 
 	return &metrics, nil
 }
 
-// ParseOutput parses the output from 'mytonctrl status' command.
+// fetch fetches the status output within the timeout.
+func (p *Parser) fetch(ctx context.Context) (string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return p.source.Fetch(ctx)
+}
+
+// withTimeout returns a context limited to the timeout of a single command.
+func (p *Parser) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, p.timeout)
+}
+
+var (
+	ansiEscape      = regexp.MustCompile(`\x1B[@-_][0-?]*[ -/]*[@-~]`)
+	loadAverageLine = regexp.MustCompile(`^Load average\[(\d+)\]:(.*)$`)
+	bracketedValues = regexp.MustCompile(`([\w-]+):\s*\[([^\]]*)\]`)
+)
+
+// ParseOutput parses the output from 'mytonctrl status' command. Output
+// containing a JSON status object is parsed as JSON, anything else as the
+// console text.
 //
-//nolint:funlen,gocyclo // This function is long due to the number of fields to parse.
+//nolint:funlen,gocyclo,gocognit,cyclop // This function is long due to the number of fields to parse.
 func (p *Parser) ParseOutput(output string) (LiteServerMetrics, error) {
 	if output == "" {
 		return LiteServerMetrics{}, errors.New("empty input")
 	}
+	if data, ok := extractJSON(output, "{", "}"); ok {
+		return parseJSONOutput(data)
+	}
+
 	m := LiteServerMetrics{}
 	scanner := bufio.NewScanner(strings.NewReader(output))
 	for scanner.Scan() {
@@ -120,111 +301,231 @@ func (p *Parser) ParseOutput(output string) (LiteServerMetrics, error) {
 		// TON Network Status
 		case strings.HasPrefix(line, "Network name:"):
 			m.NetworkName = extractValue(line, "Network name:")
+			m.setField("network_name", m.NetworkName != "")
 		case strings.HasPrefix(line, "Number of validators:"):
-			value := extractValue(line, "Number of validators:")
-			m.OnlineValidators, m.AllValidators = parseValidators(value)
+			var ok bool
+			m.OnlineValidators, m.AllValidators, ok = parseValidators(extractValue(line, "Number of validators:"))
+			m.setField("online_validators", ok)
+			m.setField("all_validators", ok)
 		case strings.HasPrefix(line, "Number of shardchains:"):
-			m.NumberOfShardchains = parseFloat(extractValue(line, "Number of shardchains:"))
+			var ok bool
+			m.NumberOfShardchains, ok = parseFloat(extractValue(line, "Number of shardchains:"))
+			m.setField("number_of_shardchains", ok)
 		case strings.HasPrefix(line, "Number of offers:"):
-			value := extractValue(line, "Number of offers:")
-			m.NewOffers, m.AllOffers = parseOffersOrComplaints(value)
+			var ok bool
+			m.NewOffers, m.AllOffers, ok = parseOffersOrComplaints(extractValue(line, "Number of offers:"))
+			m.setField("new_offers", ok)
+			m.setField("all_offers", ok)
 		case strings.HasPrefix(line, "Number of complaints:"):
-			value := extractValue(line, "Number of complaints:")
-			m.NewComplaints, m.AllComplaints = parseOffersOrComplaints(value)
+			var ok bool
+			m.NewComplaints, m.AllComplaints, ok = parseOffersOrComplaints(extractValue(line, "Number of complaints:"))
+			m.setField("new_complaints", ok)
+			m.setField("all_complaints", ok)
 		case strings.HasPrefix(line, "Election status:"):
 			m.ElectionStatus = extractValue(line, "Election status:")
+			m.setField("election_status", m.ElectionStatus != "")
 
 		// Local Validator Status
 		case strings.HasPrefix(line, "Validator index:"):
-			m.ValidatorIndex = parseFloat(extractValue(line, "Validator index:"))
+			var ok bool
+			m.ValidatorIndex, ok = parseFloat(extractValue(line, "Validator index:"))
+			m.setField("validator_index", ok)
 		case strings.HasPrefix(line, "ADNL address of local validator:"):
 			m.AdnlAddress = extractValue(line, "ADNL address of local validator:")
+			m.setField("adnl_address", m.AdnlAddress != "")
+		case strings.HasPrefix(line, "Public ADNL address of node:"):
+			m.PublicAdnlAddress = extractValue(line, "Public ADNL address of node:")
+			m.setField("public_adnl_address", m.PublicAdnlAddress != "")
 		case strings.HasPrefix(line, "Local validator wallet address:"):
 			m.WalletAddress = extractValue(line, "Local validator wallet address:")
+			m.setField("wallet_address", m.WalletAddress != "")
 		case strings.HasPrefix(line, "Local validator wallet balance:"):
-			m.WalletBalance = parseFloat(extractValue(line, "Local validator wallet balance:"))
+			var ok bool
+			value := extractValue(line, "Local validator wallet balance:")
+			m.WalletBalance, ok = parseFloat(value)
+			m.setField("wallet_balance", ok)
+			m.LocalValidatorWalletBalanceNanotons, ok = parseNanotons(value)
+			m.setField("local_validator_wallet_balance_nanotons", ok)
+		case strings.HasPrefix(line, "Load average["):
+			match := loadAverageLine.FindStringSubmatch(line)
+			if match == nil {
+				m.setField("cpu_count", false)
+				m.setField("load_average", false)
+				break
+			}
+			var ok bool
+			m.CPUCount, ok = parseFloat(match[1])
+			m.setField("cpu_count", ok)
+			m.LoadAverage1m, m.LoadAverage5m, m.LoadAverage15m, ok = parseLoadAverages(match[2])
+			m.setField("load_average", ok)
+		case strings.HasPrefix(line, "Network load average (Mbit/s):"):
+			var ok bool
+			value := extractValue(line, "Network load average (Mbit/s):")
+			m.NetworkLoadAverage1m, m.NetworkLoadAverage5m, m.NetworkLoadAverage15m, ok = parseLoadAverages(value)
+			m.setField("network_load_average", ok)
+		case strings.HasPrefix(line, "Memory load:"):
+			// Handle "Memory load: ram:[17.31 Gb, 14.0%], swap:[0.0 Gb, 0.0%]"
+			for _, match := range bracketedValues.FindAllStringSubmatch(line, -1) {
+				used, percent, ok := parseUsage(match[2])
+				switch match[1] {
+				case "ram":
+					m.MemoryRAMUsedGB, m.MemoryRAMUsagePercent = used, percent
+					m.setField("memory_ram", ok)
+				case "swap":
+					m.MemorySwapUsedGB, m.MemorySwapUsagePercent = used, percent
+					m.setField("memory_swap", ok)
+				}
+			}
+		case strings.HasPrefix(line, "Disks load average (MB/s):"):
+			// Handle "Disks load average (MB/s): nvme0n1:[0.18, 0.15%], nvme1n1:[0.0, 0.0%]"
+			allOK := true
+			for _, match := range bracketedValues.FindAllStringSubmatch(line, -1) {
+				throughput, utilization, ok := parseUsage(match[2])
+				if !ok {
+					allOK = false
+					continue
+				}
+				m.DisksLoad = append(m.DisksLoad, DiskLoad{
+					Device:             match[1],
+					ThroughputMBs:      throughput,
+					UtilizationPercent: utilization,
+				})
+			}
+			m.setField("disks_load", allOK)
 		case strings.HasPrefix(line, "Mytoncore status:"):
-			status, uptime := parseStatusAndUptime(extractValue(line, "Mytoncore status:"))
+			status, uptime, precision, ok := parseStatusAndUptime(extractValue(line, "Mytoncore status:"))
 			m.MytoncoreStatus = status
-			m.MytoncoreUptimeSeconds = uptime
+			m.MytoncoreUptimeSeconds, m.MytoncoreUptimePrecision = uptime, precision
+			m.setField("mytoncore_status", status != "")
+			m.setField("mytoncore_uptime_seconds", ok)
 		case strings.HasPrefix(line, "Local validator status:"):
-			status, uptime := parseStatusAndUptime(extractValue(line, "Local validator status:"))
+			status, uptime, precision, ok := parseStatusAndUptime(extractValue(line, "Local validator status:"))
 			m.LocalValidatorStatus = status
-			m.LocalValidatorUptimeSeconds = uptime
+			m.LocalValidatorUptimeSeconds, m.LocalValidatorUptimePrecision = uptime, precision
+			m.setField("local_validator_status", status != "")
+			m.setField("local_validator_uptime_seconds", ok)
 		case strings.HasPrefix(line, "Local validator out of sync:"):
-			m.LocalValidatorOutOfSyncSeconds = parseFloat(extractValue(line, "Local validator out of sync:"))
+			var ok bool
+			m.LocalValidatorOutOfSyncSeconds, m.LocalValidatorOutOfSyncPrecision, ok = parseDuration(
+				extractValue(line, "Local validator out of sync:"))
+			m.setField("local_validator_out_of_sync_seconds", ok)
 		case strings.HasPrefix(line, "Local validator last state serialization:"):
-			m.LocalValidatorLastStateSerializationBlocks = parseFloat(extractValue(line, "Local validator last state serialization:"))
+			var ok bool
+			m.LocalValidatorLastStateSerializationBlocks, ok = parseFloat(extractValue(line, "Local validator last state serialization:"))
+			m.setField("local_validator_last_state_serialization_blocks", ok)
 		case strings.HasPrefix(line, "Local validator database size:"):
 			// Assuming the format is "25.89 Gb, 2.4%"
 			value := extractValue(line, "Local validator database size:")
-			parts := strings.Split(value, ",")
-			if len(parts) >= 1 {
-				m.LocalValidatorDatabaseSizeGB = parseFloat(parts[0])
+			size, usage, _ := strings.Cut(value, ",")
+			bytes, ok := parseSize(size)
+			m.LocalValidatorDatabaseSizeBytes = bytes
+			m.LocalValidatorDatabaseSizeGB = bytes / sizeUnits["gb"]
+			m.setField("local_validator_database_size_bytes", ok)
+			m.setField("local_validator_database_size_gb", ok)
+			// Formats printing only the size have no usage, leave it unset.
+			if strings.TrimSpace(usage) != "" {
+				m.LocalValidatorDatabaseDiskUsageRatio, ok = parsePercent(usage)
+				m.setField("local_validator_database_disk_usage_ratio", ok)
 			}
 		case strings.HasPrefix(line, "Version mytonctrl:"):
 			m.VersionMytonctrl = extractValue(line, "Version mytonctrl:")
+			m.setField("version_mytonctrl", m.VersionMytonctrl != "")
 		case strings.HasPrefix(line, "Version validator:"):
 			m.VersionValidator = extractValue(line, "Version validator:")
+			m.setField("version_validator", m.VersionValidator != "")
 
 		// TON Network Configuration
 		case strings.HasPrefix(line, "Configurator address:"):
 			m.ConfiguratorAddress = extractValue(line, "Configurator address:")
+			m.setField("configurator_address", m.ConfiguratorAddress != "")
 		case strings.HasPrefix(line, "Elector address:"):
 			m.ElectorAddress = extractValue(line, "Elector address:")
-		case strings.HasPrefix(line, "Validation period:"):
+			m.setField("elector_address", m.ElectorAddress != "")
+		case strings.HasPrefix(line, "Validation period:"),
+			strings.Contains(line, "Minimum stake:"):
 			// Handle "Validation period: 7200, Duration of elections: 2400-180, Hold period: 900"
-			pairs := strings.Split(line, ",")
-			for _, pair := range pairs {
-				pair = strings.TrimSpace(pair)
-				switch {
-				case strings.HasPrefix(pair, "Validation period:"):
-					m.ValidationPeriodSeconds = parseFloat(extractValue(pair, "Validation period:"))
-				case strings.HasPrefix(pair, "Duration of elections:"):
-					// Assuming format "2400-180"
-					subParts := strings.Split(extractValue(pair, "Duration of elections:"), "-")
-					if len(subParts) >= 1 {
-						m.DurationOfElectionsSeconds = parseFloat(subParts[0])
-					}
-				case strings.HasPrefix(pair, "Hold period:"):
-					m.HoldPeriodSeconds = parseFloat(extractValue(pair, "Hold period:"))
-				}
-			}
-		case strings.Contains(line, "Minimum stake:"):
-			// Handle "Minimum stake: 10000.0, Maximum stake: 5000000.0"
-			parts := strings.Split(line, ",")
-			for _, part := range parts {
-				part = strings.TrimSpace(part)
-				switch {
-				case strings.HasPrefix(part, "Minimum stake:"):
-					m.MinimumStakeTONs = parseFloat(extractValue(part, "Minimum stake:"))
-				case strings.HasPrefix(part, "Maximum stake:"):
-					m.MaximumStakeTONs = parseFloat(extractValue(part, "Maximum stake:"))
-				}
+			// and "Minimum stake: 10000.0, Maximum stake: 5000000.0"
+			for _, pair := range strings.Split(line, ",") {
+				m.parseNetworkConfigPair(strings.TrimSpace(pair))
 			}
 		// TON Timestamps
 		case strings.HasPrefix(line, "TON network was launched:"):
-			m.NetworkLaunchedTimestamp = parseTimestamp(extractValue(line, "TON network was launched:"))
+			var ok bool
+			m.NetworkLaunchedTimestamp, ok = parseTimestamp(extractValue(line, "TON network was launched:"))
+			m.setField("network_launched_timestamp", ok)
 		case strings.HasPrefix(line, "Start of the validation cycle:"):
-			m.StartValidationCycleTimestamp = parseTimestamp(extractValue(line, "Start of the validation cycle:"))
+			var ok bool
+			m.StartValidationCycleTimestamp, ok = parseTimestamp(extractValue(line, "Start of the validation cycle:"))
+			m.setField("start_validation_cycle_timestamp", ok)
 		case strings.HasPrefix(line, "End of the validation cycle:"):
-			m.EndValidationCycleTimestamp = parseTimestamp(extractValue(line, "End of the validation cycle:"))
+			var ok bool
+			m.EndValidationCycleTimestamp, ok = parseTimestamp(extractValue(line, "End of the validation cycle:"))
+			m.setField("end_validation_cycle_timestamp", ok)
 		case strings.HasPrefix(line, "Start of elections:"):
-			m.StartElectionsTimestamp = parseTimestamp(extractValue(line, "Start of elections:"))
+			var ok bool
+			m.StartElectionsTimestamp, ok = parseTimestamp(extractValue(line, "Start of elections:"))
+			m.setField("start_elections_timestamp", ok)
 		case strings.HasPrefix(line, "End of elections:"):
-			m.EndElectionsTimestamp = parseTimestamp(extractValue(line, "End of elections:"))
+			var ok bool
+			m.EndElectionsTimestamp, ok = parseTimestamp(extractValue(line, "End of elections:"))
+			m.setField("end_elections_timestamp", ok)
 		case strings.HasPrefix(line, "Beginning of the next elections:"):
-			m.BeginNextElectionsTimestamp = parseTimestamp(extractValue(line, "Beginning of the next elections:"))
+			var ok bool
+			m.BeginNextElectionsTimestamp, ok = parseTimestamp(extractValue(line, "Beginning of the next elections:"))
+			m.setField("begin_next_elections_timestamp", ok)
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		return LiteServerMetrics{}, fmt.Errorf("scanner error: %w", err)
 	}
+	m.setActiveElectionID()
 
 	return m, nil
 }
 
+// setActiveElectionID derives the ID of the active election, which status does
+// not print. The elector names an election after the start of the validation
+// cycle it elects, the end of the current cycle, and there is none while
+// elections are closed.
+func (m *LiteServerMetrics) setActiveElectionID() {
+	switch {
+	case m.Parsed("election_status") && m.ElectionStatus == "closed":
+		m.ActiveElectionID = 0
+	case m.Parsed("election_status") && m.ElectionStatus == "open" && m.Parsed("end_validation_cycle_timestamp"):
+		m.ActiveElectionID = m.EndValidationCycleTimestamp
+	default:
+		return
+	}
+	m.setField("active_election_id", true)
+}
+
+// setField records that the field was present in the output and whether its value parsed.
+// A field that appears several times keeps the first failure.
+func (m *LiteServerMetrics) setField(field string, ok bool) {
+	if m.ParsedFields == nil {
+		m.ParsedFields = make(map[string]bool)
+	}
+	if prev, seen := m.ParsedFields[field]; seen && !prev {
+		return
+	}
+	m.ParsedFields[field] = ok
+}
+
+// replaceField records whether the field was parsed successfully, replacing
+// an earlier result such as an error of the status text.
+func (m *LiteServerMetrics) replaceField(field string, ok bool) {
+	if m.ParsedFields == nil {
+		m.ParsedFields = make(map[string]bool)
+	}
+	m.ParsedFields[field] = ok
+}
+
+// Parsed reports whether the field was present in the output and parsed successfully.
+func (m *LiteServerMetrics) Parsed(field string) bool {
+	return m.ParsedFields[field]
+}
+
 // extractValue removes the prefix from the line and returns the trimmed value.
 func extractValue(line, prefix string) string {
 	return strings.TrimSpace(strings.TrimPrefix(line, prefix))
@@ -234,83 +535,113 @@ func cleanLine(line string) string {
 	return ansiEscape.ReplaceAllString(line, "")
 }
 
-// parseFloat safely parses a float from string, returns -1 on failure.
-func parseFloat(value string) float64 {
+// parseFloat safely parses a float from string, reporting whether it succeeded.
+func parseFloat(value string) (float64, bool) {
 	// Handle cases like "123.45 TON" by taking the first field
 	fields := strings.Fields(value)
 	if len(fields) == 0 {
-		return -1
+		return 0, false
 	}
 	num, err := strconv.ParseFloat(fields[0], 64)
 	if err != nil {
-		return -1
+		return 0, false
 	}
-	return num
+	return num, true
 }
 
 // parseTimestamp parses a timestamp string in the format "02.01.2006 15:04:05 UTC" and returns Unix time.
-func parseTimestamp(value string) float64 {
+func parseTimestamp(value string) (float64, bool) {
 	layout := "02.01.2006 15:04:05 UTC"
 	t, err := time.Parse(layout, value)
 	if err != nil {
-		return -1
+		return 0, false
 	}
-	return float64(t.Unix())
+	return float64(t.Unix()), true
 }
 
 // parseValidators parses the "Number of validators" value.
-func parseValidators(value string) (float64, float64) {
-	parts := strings.Split(value, "(")
-	if len(parts) != 2 {
-		return -1, -1
-	}
-	onlineStr := strings.TrimSpace(parts[0])
-	allStr := strings.TrimSuffix(strings.TrimSpace(parts[1]), ")")
-	return parseFloat(onlineStr), parseFloat(allStr)
+func parseValidators(value string) (float64, float64, bool) {
+	return parseCountPair(value)
 }
 
 // parseOffersOrComplaints parses the "Number of offers/complaints" value.
-func parseOffersOrComplaints(value string) (float64, float64) {
+func parseOffersOrComplaints(value string) (float64, float64, bool) {
+	return parseCountPair(value)
+}
+
+// parseCountPair parses a "23(26)" pair of counts.
+func parseCountPair(value string) (float64, float64, bool) {
 	parts := strings.Split(value, "(")
 	if len(parts) != 2 {
-		return -1, -1
+		return 0, 0, false
 	}
-	newStr := strings.TrimSpace(parts[0])
-	allStr := strings.TrimSuffix(strings.TrimSpace(parts[1]), ")")
-	return parseFloat(newStr), parseFloat(allStr)
+	first, firstOK := parseFloat(strings.TrimSpace(parts[0]))
+	second, secondOK := parseFloat(strings.TrimSuffix(strings.TrimSpace(parts[1]), ")"))
+	return first, second, firstOK && secondOK
 }
 
-// parseStatusAndUptime parses status and uptime from a value string.
-func parseStatusAndUptime(value string) (string, float64) {
-	// Expected format: "working, 14 days"
-	parts := strings.SplitN(value, ",", 2)
-	if len(parts) != 2 {
-		return "unknown", -1
+// parseLoadAverages parses a "1m, 5m, 15m" triple of load averages.
+func parseLoadAverages(value string) (float64, float64, float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return 0, 0, 0, false
 	}
-	return strings.TrimSpace(parts[0]), convertUptimeToSeconds(strings.TrimSpace(parts[1]))
+	load1m, ok1m := parseFloat(parts[0])
+	load5m, ok5m := parseFloat(parts[1])
+	load15m, ok15m := parseFloat(parts[2])
+	return load1m, load5m, load15m, ok1m && ok5m && ok15m
 }
 
-// convertUptimeToSeconds converts uptime strings like "14 days", "16 days", "3 s" etc., to seconds.
-func convertUptimeToSeconds(uptimeStr string) float64 {
-	parts := strings.Fields(uptimeStr)
-	if len(parts) < 2 {
-		return -1
-	}
-	timeValue, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return -1
+// parseUsage parses an "amount, percent" pair like "17.31 Gb, 14.0%".
+func parseUsage(value string) (float64, float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
 	}
-	unit := strings.ToLower(parts[1])
+	amount, amountOK := parseFloat(parts[0])
+	percent, percentOK := parseFloat(strings.TrimSuffix(strings.TrimSpace(parts[1]), "%"))
+	return amount, percent, amountOK && percentOK
+}
+
+// parseNetworkConfigPair parses one "name: value" pair of the network configuration lines.
+func (m *LiteServerMetrics) parseNetworkConfigPair(pair string) {
+	var ok bool
 	switch {
-	case strings.Contains(unit, "day"):
-		return float64(timeValue * 86400)
-	case strings.Contains(unit, "hour"):
-		return float64(timeValue * 3600)
-	case strings.Contains(unit, "minute"):
-		return float64(timeValue * 60)
-	case strings.Contains(unit, "second"):
-		return float64(timeValue)
-	default:
-		return -1
+	case strings.HasPrefix(pair, "Validation period:"):
+		m.ValidationPeriodSeconds, ok = parseFloat(extractValue(pair, "Validation period:"))
+		m.setField("validation_period_seconds", ok)
+	case strings.HasPrefix(pair, "Duration of elections:"):
+		// Assuming format "2400-180": elections start 2400 seconds and end 180 seconds before the cycle
+		start, end, found := strings.Cut(extractValue(pair, "Duration of elections:"), "-")
+		m.ElectionsStartBeforeSeconds, ok = parseFloat(start)
+		m.DurationOfElectionsSeconds = m.ElectionsStartBeforeSeconds
+		m.setField("duration_of_elections_seconds", ok)
+		m.setField("elections_start_before_seconds", ok)
+		// Formats printing only the duration have no end, leave it unset.
+		if found {
+			m.ElectionsEndBeforeSeconds, ok = parseFloat(end)
+			m.setField("elections_end_before_seconds", ok)
+		}
+	case strings.HasPrefix(pair, "Hold period:"):
+		m.HoldPeriodSeconds, ok = parseFloat(extractValue(pair, "Hold period:"))
+		m.setField("hold_period_seconds", ok)
+	case strings.HasPrefix(pair, "Minimum stake:"):
+		m.MinimumStakeTONs, ok = parseFloat(extractValue(pair, "Minimum stake:"))
+		m.setField("minimum_stake_tons", ok)
+	case strings.HasPrefix(pair, "Maximum stake:"):
+		m.MaximumStakeTONs, ok = parseFloat(extractValue(pair, "Maximum stake:"))
+		m.setField("maximum_stake_tons", ok)
+	}
+}
+
+// parseStatusAndUptime parses status and uptime from a value string.
+// The status is returned even when the uptime is missing or malformed.
+func parseStatusAndUptime(value string) (string, float64, string, bool) {
+	// Expected format: "working, 14 days" or "working, 1 day, 3 hours"
+	parts := strings.SplitN(value, ",", 2)
+	if len(parts) != 2 {
+		return strings.TrimSpace(value), 0, "", false
 	}
+	uptime, precision, ok := parseDuration(parts[1])
+	return strings.TrimSpace(parts[0]), uptime, precision, ok
 }
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// accountStatusCommand prints the status, balance and history of the account
+// whose address follows it.
+const accountStatusCommand = "vas"
+
+// accountStates are the account states printed by 'vas', exported by the
+// account_status state set.
+var accountStates = []string{"active", "uninit", "frozen", "empty", stateUnknown}
+
+// WatchedAccount is an additional wallet or contract, such as a nominator pool,
+// whose balance and state are read with 'vas'.
+type WatchedAccount struct {
+	Address string
+	// Alias names the account in the alias label of account_balance.
+	Alias string
+}
+
+// AccountStatus is the state and balance of a watched account.
+type AccountStatus struct {
+	Address         string   `json:"address"`
+	Alias           string   `json:"alias"`
+	State           string   `json:"state"`
+	BalanceNanotons Nanotons `json:"balance_nanotons"`
+}
+
+// accountQuery is the result of the latest 'vas' run for an account.
+type accountQuery struct {
+	queried time.Time
+	// status is the last status read, kept when a later query fails.
+	status AccountStatus
+	ok     bool
+}
+
+// parseAccountStatus parses the status table of the 'vas' output:
+//
+//	Address                                           Status  Balance          Version
+//	EQCkR1cGmnsE45N4K0otPl5EnxnRakmGqeJUNua5fkWhales  active  95290.938201014  v3r2
+func parseAccountStatus(output string) (string, Nanotons, error) {
+	rows, err := parseTable(output, "Address", "Status", "Balance")
+	if err != nil {
+		return "", 0, err
+	}
+	if len(rows) == 0 {
+		return "", 0, errors.New("no account status in output")
+	}
+
+	balance, ok := parseNanotons(rows[0]["Balance"])
+	if !ok {
+		return "", 0, fmt.Errorf("invalid balance %q", rows[0]["Balance"])
+	}
+	return strings.ToLower(rows[0]["Status"]), balance, nil
+}
+
+// parseTable parses the first table printed by mytonctrl whose header starts
+// with first and has the columns, up to the first empty line. The rows map
+// the header columns to their values, which must not contain spaces.
+func parseTable(output, first string, columns ...string) ([]map[string]string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	var (
+		header []string
+		rows   []map[string]string
+	)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(cleanLine(scanner.Text())), "MyTonCtrl>"))
+		if header == nil {
+			if len(fields) > 0 && fields[0] == first && containsAll(fields, columns) {
+				header = fields
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			break
+		}
+		if len(fields) != len(header) {
+			return nil, fmt.Errorf("malformed %s table row", strings.ToLower(first))
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = fields[i]
+		}
+		rows = append(rows, row)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("no %s table in output", strings.ToLower(first))
+	}
+
+	return rows, nil
+}
+
+func containsAll(values, wanted []string) bool {
+	for _, value := range wanted {
+		if !slices.Contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// runAccounts reads the watched accounts, recording a failure as an "accounts"
+// field error. Every account is queried at most once per accounts interval,
+// in between its last status is reused.
+func (p *Parser) runAccounts(ctx context.Context, m *LiteServerMetrics) error {
+	if len(p.options.Accounts) == 0 {
+		return nil
+	}
+
+	p.accountsMutex.Lock()
+	defer p.accountsMutex.Unlock()
+	if p.accounts == nil {
+		p.accounts = make(map[string]accountQuery, len(p.options.Accounts))
+	}
+
+	now := p.now()
+	var errs []error
+	for _, account := range p.options.Accounts {
+		query, ok := p.accounts[account.Address]
+		if !ok || now.Sub(query.queried) >= p.options.AccountsInterval {
+			query.queried = now
+			state, balance, err := p.queryAccount(ctx, account.Address)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("account %s: %w", account.Address, err))
+			} else {
+				query.status = AccountStatus{
+					Address:         account.Address,
+					Alias:           account.Alias,
+					State:           state,
+					BalanceNanotons: balance,
+				}
+				query.ok = true
+			}
+			p.accounts[account.Address] = query
+		}
+
+		if query.ok {
+			m.Accounts = append(m.Accounts, query.status)
+		}
+	}
+
+	m.setField("accounts", len(errs) == 0)
+	return errors.Join(errs...)
+}
+
+// queryAccount runs 'vas' for the account at address.
+func (p *Parser) queryAccount(ctx context.Context, address string) (string, Nanotons, error) {
+	output, err := p.run(ctx, accountStatusCommand+" "+address)
+	if err != nil {
+		return "", 0, err
+	}
+	return parseAccountStatus(output)
+}
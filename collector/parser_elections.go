@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// electionEntriesCommand prints the entries of the current election as a JSON
+// object keyed by ADNL address.
+const electionEntriesCommand = "el --json"
+
+// States of the local validator stake: frozen while the elector holds it,
+// returned once the hold period of its election is over.
+const (
+	stakeStateFrozen   = "frozen"
+	stakeStateReturned = "returned"
+)
+
+// stakeStates are the states of the local validator stake, exported by the
+// local_validator_stake_state state set.
+var stakeStates = []string{stakeStateFrozen, stakeStateReturned, stateUnknown}
+
+// parseElectionEntries parses the 'el --json' output. The entries are the
+// ones mytoncore saves under saveElections in its database.
+func parseElectionEntries(output string) (map[string]mytoncoreElectionEntry, error) {
+	data, ok := extractJSON(output, "{", "}")
+	if !ok {
+		return nil, errors.New("no election entries in output")
+	}
+
+	var entries map[string]mytoncoreElectionEntry
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("invalid election entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// runElectionEntries runs the election entries pass when enabled, recording a
+// failure as a "local_validator_stake_tons" field error. The stake of the
+// local validator is its entry matching the ADNL address of the status. The
+// latest election it took part in is remembered for the stake state.
+func (p *Parser) runElectionEntries(ctx context.Context, m *LiteServerMetrics) error {
+	if !p.options.ElectionEntries || !m.Parsed("adnl_address") {
+		return nil
+	}
+
+	output, err := p.run(ctx, electionEntriesCommand)
+	if err != nil {
+		m.setField("local_validator_stake_tons", false)
+		return fmt.Errorf("election entries: %w", err)
+	}
+	entries, err := parseElectionEntries(output)
+	if err != nil {
+		m.setField("local_validator_stake_tons", false)
+		return fmt.Errorf("election entries: %w", err)
+	}
+
+	m.LocalValidatorStakeTONs = entries[m.AdnlAddress].Stake
+	m.setField("local_validator_stake_tons", true)
+
+	p.electionsMutex.Lock()
+	if m.LocalValidatorStakeTONs > 0 && m.Parsed("active_election_id") {
+		p.latestElectionID = max(p.latestElectionID, m.ActiveElectionID)
+	}
+	latest := p.latestElectionID
+	p.electionsMutex.Unlock()
+
+	if latest > 0 && !m.Parsed("latest_participated_election_id") {
+		m.LatestParticipatedElectionID = latest
+		m.setField("latest_participated_election_id", true)
+	}
+	m.setStakeState(p.now())
+
+	return nil
+}
+
+// setStakeState sets the state of the stake made in the latest election the
+// local validator took part in. The election id is the start of the validation
+// period, the elector holds the stake until the period and the hold period
+// after it are over.
+func (m *LiteServerMetrics) setStakeState(now time.Time) {
+	if !m.Parsed("latest_participated_election_id") ||
+		!m.Parsed("validation_period_seconds") || !m.Parsed("hold_period_seconds") {
+		return
+	}
+
+	end := m.LatestParticipatedElectionID + m.ValidationPeriodSeconds + m.HoldPeriodSeconds
+	m.LocalValidatorStakeState = stakeStateReturned
+	if float64(now.Unix()) < end {
+		m.LocalValidatorStakeState = stakeStateFrozen
+	}
+	m.setField("local_validator_stake_state", true)
+}
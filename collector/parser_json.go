@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// jsonFields maps the JSON names of the LiteServerMetrics fields to their index.
+var jsonFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(LiteServerMetrics{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && name != "parsed_fields" {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// jsonFieldGroups maps the JSON names of values that the text parser reads
+// from one line to their shared ParsedFields key.
+var jsonFieldGroups = map[string]string{
+	"load_average_1m":           "load_average",
+	"load_average_5m":           "load_average",
+	"load_average_15m":          "load_average",
+	"network_load_average_1m":   "network_load_average",
+	"network_load_average_5m":   "network_load_average",
+	"network_load_average_15m":  "network_load_average",
+	"memory_ram_used_gb":        "memory_ram",
+	"memory_ram_usage_percent":  "memory_ram",
+	"memory_swap_used_gb":       "memory_swap",
+	"memory_swap_usage_percent": "memory_swap",
+	"master_blocks_created":     "blocks_created",
+	"work_blocks_created":       "blocks_created",
+	"master_blocks_expected":    "blocks_expected",
+	"work_blocks_expected":      "blocks_expected",
+	"validator_set_dropped":     "validators",
+}
+
+// jsonPrecisions maps the JSON names of duration precisions to the ParsedFields
+// key of their duration. A precision without its value does not mark the
+// duration parsed, only a precision of the wrong type is recorded as an error.
+var jsonPrecisions = map[string]string{
+	"mytoncore_uptime_precision":            "mytoncore_uptime_seconds",
+	"local_validator_uptime_precision":      "local_validator_uptime_seconds",
+	"local_validator_out_of_sync_precision": "local_validator_out_of_sync_seconds",
+}
+
+// extractJSON returns the JSON value printed by mytonctrl that is delimited by
+// open and closing, such as "{" and "}" for an object, skipping the console
+// banner, log lines and prompts around it. It reports false when there is none.
+func extractJSON(output, open, closing string) (string, bool) {
+	end := strings.LastIndex(output, closing)
+	offset := 0
+	for _, line := range strings.SplitAfter(output, "\n") {
+		trimmed := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "MyTonCtrl>"))
+		start := offset + strings.Index(line, open)
+		offset += len(line)
+		if !strings.HasPrefix(trimmed, open) || end < start {
+			continue
+		}
+
+		// Log lines such as "[debug] ..." start like an array, so keep looking.
+		if candidate := output[start : end+1]; json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// parseJSONOutput parses a status object using the LiteServerMetrics JSON field
+// names, the same ones the print command emits and the mytoncore db source
+// converts its database to. mytonctrl has no JSON status, its JSON lists are
+// read by the structured pass. Unknown keys are ignored and a
+// value of the wrong type is recorded as a field parse error.
+func parseJSONOutput(data string) (LiteServerMetrics, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return LiteServerMetrics{}, err
+	}
+
+	m := LiteServerMetrics{}
+	value := reflect.ValueOf(&m).Elem()
+	for name, message := range raw {
+		index, ok := jsonFields[name]
+		if !ok || string(message) == "null" {
+			continue
+		}
+
+		err := json.Unmarshal(message, value.Field(index).Addr().Interface())
+		if field, ok := jsonPrecisions[name]; ok {
+			if err != nil {
+				m.setField(field, false)
+			}
+			continue
+		}
+		if group, ok := jsonFieldGroups[name]; ok {
+			name = group
+		}
+		m.setField(name, err == nil)
+	}
+
+	return m, nil
+}
+
+// Support of the mytonctrl build for the JSON lists read by the structured
+// pass, detected from its first 'vl --json' output.
+const (
+	jsonSupportUnknown = iota
+	jsonSupported
+	jsonUnsupported
+)
+
+// runStructured runs the structured pass when enabled. It reads the validator,
+// offer and complaint counts from the JSON lists printed by 'vl --json',
+// 'ol --json' and 'cl --json' in place of the status text. mytonctrl builds
+// whose first 'vl --json' output has no JSON list are not asked again and keep
+// the text values, as do failed commands.
+func (p *Parser) runStructured(ctx context.Context, m *LiteServerMetrics) error {
+	if !p.options.Structured {
+		return nil
+	}
+	p.jsonMutex.Lock()
+	support := p.jsonSupport
+	p.jsonMutex.Unlock()
+	if support == jsonUnsupported {
+		return nil
+	}
+
+	output, err := p.run(ctx, validatorListCommand)
+	if err != nil {
+		return fmt.Errorf("structured validator list: %w", err)
+	}
+	validators, err := parseValidatorList(output)
+	if support == jsonSupportUnknown {
+		support = jsonSupported
+		if err != nil {
+			support = jsonUnsupported
+			log.Printf("mytonctrl prints no JSON validator list, reading the counts from the status text")
+		}
+		p.jsonMutex.Lock()
+		p.jsonSupport = support
+		p.jsonMutex.Unlock()
+	}
+	if err != nil {
+		if support == jsonUnsupported {
+			return nil
+		}
+		return fmt.Errorf("structured validator list: %w", err)
+	}
+
+	online := 0
+	for _, validator := range validators {
+		if validator.Online {
+			online++
+		}
+	}
+	m.OnlineValidators, m.AllValidators = float64(online), float64(len(validators))
+	m.replaceField("online_validators", true)
+	m.replaceField("all_validators", true)
+
+	output, err = p.run(ctx, offersListCommand)
+	if err == nil {
+		var offers []offersListEntry
+		if offers, err = parseOffersList(output); err == nil {
+			m.AllOffers = float64(len(offers))
+			m.replaceField("all_offers", true)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("structured offers list: %w", err)
+	}
+
+	output, err = p.run(ctx, complaintsListCommand)
+	if err == nil {
+		var complaints []complaintsListEntry
+		if complaints, err = parseComplaintsList(output); err == nil {
+			m.AllComplaints = float64(len(complaints))
+			m.replaceField("all_complaints", true)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("structured complaints list: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Commands printing the offers and complaints of the current round as JSON.
+const (
+	offersListCommand     = "ol --json"
+	complaintsListCommand = "cl --json"
+)
+
+// offersListEntry is an offer of the 'ol --json' output.
+type offersListEntry struct {
+	Hash   string `json:"hash"`
+	Config struct {
+		ID float64 `json:"id"`
+	} `json:"config"`
+	VotedValidators []json.RawMessage `json:"votedValidators"`
+	ApprovedPercent float64           `json:"approvedPercent"`
+	IsPassed        bool              `json:"isPassed"`
+}
+
+// complaintsListEntry is a complaint of the 'cl --json' output.
+type complaintsListEntry struct {
+	ElectionID    json.Number `json:"electionId"`
+	Pseudohash    string      `json:"pseudohash"`
+	Adnl          string      `json:"adnl"`
+	SuggestedFine float64     `json:"suggestedFine"`
+}
+
+// parseOffersList parses the 'ol --json' output.
+func parseOffersList(output string) ([]offersListEntry, error) {
+	data, ok := extractJSON(output, "[", "]")
+	if !ok {
+		return nil, errors.New("no offers list in output")
+	}
+
+	var offers []offersListEntry
+	if err := json.Unmarshal([]byte(data), &offers); err != nil {
+		return nil, fmt.Errorf("invalid offers list: %w", err)
+	}
+
+	return offers, nil
+}
+
+// parseComplaintsList parses the 'cl --json' output, an object of complaints
+// keyed by their pseudohash.
+func parseComplaintsList(output string) ([]complaintsListEntry, error) {
+	data, ok := extractJSON(output, "{", "}")
+	if !ok {
+		return nil, errors.New("no complaints list in output")
+	}
+
+	var complaints map[string]complaintsListEntry
+	if err := json.Unmarshal([]byte(data), &complaints); err != nil {
+		return nil, fmt.Errorf("invalid complaints list: %w", err)
+	}
+
+	res := make([]complaintsListEntry, 0, len(complaints))
+	for pseudohash, complaint := range complaints {
+		if complaint.Pseudohash == "" {
+			complaint.Pseudohash = pseudohash
+		}
+		res = append(res, complaint)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Pseudohash < res[j].Pseudohash
+	})
+
+	return res, nil
+}
+
+// runOffersComplaints runs the offers and complaints pass when enabled, recording
+// a failure as an "offers_complaints" field error.
+func (p *Parser) runOffersComplaints(ctx context.Context, m *LiteServerMetrics) error {
+	if !p.options.OffersComplaints {
+		return nil
+	}
+
+	output, err := p.run(ctx, offersListCommand)
+	if err != nil {
+		m.setField("offers_complaints", false)
+		return fmt.Errorf("offers list: %w", err)
+	}
+	offers, err := parseOffersList(output)
+	if err != nil {
+		m.setField("offers_complaints", false)
+		return fmt.Errorf("offers list: %w", err)
+	}
+
+	output, err = p.run(ctx, complaintsListCommand)
+	if err != nil {
+		m.setField("offers_complaints", false)
+		return fmt.Errorf("complaints list: %w", err)
+	}
+	complaints, err := parseComplaintsList(output)
+	if err != nil {
+		m.setField("offers_complaints", false)
+		return fmt.Errorf("complaints list: %w", err)
+	}
+
+	m.setField("offers_complaints", true)
+	m.setOffers(offers)
+	m.setComplaints(complaints)
+
+	return nil
+}
+
+// setOffers sets the offers that have not passed yet.
+func (m *LiteServerMetrics) setOffers(offers []offersListEntry) {
+	m.Offers = make([]Offer, 0, len(offers))
+	for _, offer := range offers {
+		if offer.IsPassed {
+			continue
+		}
+		m.Offers = append(m.Offers, Offer{
+			Hash:            offer.Hash,
+			ConfigParamID:   offer.Config.ID,
+			Votes:           float64(len(offer.VotedValidators)),
+			ApprovedPercent: offer.ApprovedPercent,
+		})
+	}
+	m.setField("offers", true)
+}
+
+// setComplaints sets the complaints and counts the ones against the local
+// validator, which is only known when its ADNL address was parsed.
+func (m *LiteServerMetrics) setComplaints(complaints []complaintsListEntry) {
+	local := m.Parsed("adnl_address")
+
+	m.Complaints = make([]Complaint, 0, len(complaints))
+	for _, complaint := range complaints {
+		targetsLocal := local && complaint.Adnl == m.AdnlAddress
+		if targetsLocal {
+			m.ComplaintsAgainstLocalValidator++
+		}
+		m.Complaints = append(m.Complaints, Complaint{
+			Hash:         complaint.Pseudohash,
+			ElectionID:   complaint.ElectionID.String(),
+			TargetAdnl:   complaint.Adnl,
+			FineTONs:     complaint.SuggestedFine,
+			TargetsLocal: targetsLocal,
+		})
+	}
+	m.setField("complaints", true)
+	if local {
+		m.setField("complaints_against_local_validator", true)
+	}
+}
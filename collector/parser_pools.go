@@ -0,0 +1,198 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Commands printing the pools of the node and the data of a pool contract.
+const (
+	poolsListCommand = "pools_list"
+	poolDataCommand  = "get_pool_data"
+)
+
+// singlePoolVersion prefixes the contract version of single nominator pools.
+const singlePoolVersion = "spool"
+
+// poolRewardShareOne is a validator reward share of 100%, the share is kept
+// in hundredths of a percent.
+const poolRewardShareOne = 10000
+
+// States of a pool: active while its contract is active, stopped otherwise.
+const (
+	poolStateActive  = "active"
+	poolStateStopped = "stopped"
+)
+
+// poolStates are the states of a pool, exported by the pool_state state set.
+var poolStates = []string{poolStateActive, poolStateStopped, stateUnknown}
+
+// Pool is a nominator pool or single nominator pool of the node.
+type Pool struct {
+	Name        string  `json:"name"`
+	Address     string  `json:"address"`
+	Version     string  `json:"version"`
+	State       string  `json:"state"`
+	BalanceTONs float64 `json:"balance_tons"`
+	// Values read from the pool contract, nil for single nominator pools and
+	// for values mytonctrl does not print.
+	Nominators                *float64 `json:"nominators"`
+	ValidatorRewardShareRatio *float64 `json:"validator_reward_share_ratio"`
+	PendingDepositsTONs       *float64 `json:"pending_deposits_tons"`
+	PendingWithdrawals        *float64 `json:"pending_withdrawals"`
+}
+
+// poolData is the 'get_pool_data' output of a nominator pool.
+type poolData struct {
+	NominatorsCount *float64 `json:"nominatorsCount"`
+	Config          struct {
+		// ValidatorRewardShare is in hundredths of a percent.
+		ValidatorRewardShare *float64 `json:"validatorRewardShare"`
+	} `json:"config"`
+	Nominators       json.RawMessage `json:"nominators"`
+	WithdrawRequests json.RawMessage `json:"withdrawRequests"`
+}
+
+// poolNominator is a nominator of the pool data, when mytonctrl lists them.
+type poolNominator struct {
+	PendingDepositAmount *float64 `json:"pendingDepositAmount"`
+}
+
+// parsePoolsList parses the 'pools_list' table:
+//
+//	Name   Status  Balance  Version   Address
+//	pool1  active  1000.5   spool_r2  EQ...
+func parsePoolsList(output string) ([]Pool, error) {
+	if strings.Contains(output, "No data") {
+		return nil, nil
+	}
+
+	rows, err := parseTable(output, "Name", "Status", "Balance", "Version", "Address")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make([]Pool, 0, len(rows))
+	for _, row := range rows {
+		balance, ok := parseFloat(row["Balance"])
+		if !ok {
+			return nil, fmt.Errorf("invalid balance %q of pool %s", row["Balance"], row["Name"])
+		}
+		state := poolStateStopped
+		if strings.ToLower(row["Status"]) == "active" {
+			state = poolStateActive
+		}
+		pools = append(pools, Pool{
+			Name:        row["Name"],
+			Address:     row["Address"],
+			Version:     row["Version"],
+			State:       state,
+			BalanceTONs: balance,
+		})
+	}
+
+	return pools, nil
+}
+
+// parsePoolData parses the 'get_pool_data' output into pool.
+func parsePoolData(output string, pool *Pool) error {
+	data, ok := extractJSON(output, "{", "}")
+	if !ok {
+		return errors.New("no pool data in output")
+	}
+
+	var poolData poolData
+	if err := json.Unmarshal([]byte(data), &poolData); err != nil {
+		return fmt.Errorf("invalid pool data: %w", err)
+	}
+
+	pool.Nominators = poolData.NominatorsCount
+	if share := poolData.Config.ValidatorRewardShare; share != nil {
+		ratio := *share / poolRewardShareOne
+		pool.ValidatorRewardShareRatio = &ratio
+	}
+	pool.PendingWithdrawals = countJSON(poolData.WithdrawRequests)
+	pool.PendingDepositsTONs = pendingDeposits(poolData.Nominators)
+
+	return nil
+}
+
+// pendingDeposits returns the sum of the pending deposits of the nominators.
+// They are only listed with their pending deposits by some mytonctrl versions,
+// others print the raw dictionary and nil is returned.
+func pendingDeposits(data json.RawMessage) *float64 {
+	var nominators []poolNominator
+	if json.Unmarshal(data, &nominators) != nil || nominators == nil {
+		return nil
+	}
+
+	var pending float64
+	for _, nominator := range nominators {
+		if nominator.PendingDepositAmount == nil {
+			return nil
+		}
+		pending += *nominator.PendingDepositAmount
+	}
+	return &pending
+}
+
+// countJSON returns the number of elements of a JSON array or object, 0 for
+// null and nil for anything else.
+func countJSON(data json.RawMessage) *float64 {
+	var count float64
+	var list []json.RawMessage
+	var object map[string]json.RawMessage
+	switch {
+	case len(data) == 0:
+		return nil
+	case json.Unmarshal(data, &list) == nil:
+		count = float64(len(list))
+	case json.Unmarshal(data, &object) == nil:
+		count = float64(len(object))
+	default:
+		return nil
+	}
+	return &count
+}
+
+// runPools runs the pools pass when enabled, recording a failure as a "pools"
+// field error. Single nominator pools have no pool data, only their balance
+// and state are read.
+func (p *Parser) runPools(ctx context.Context, m *LiteServerMetrics) error {
+	if !p.options.Pools {
+		return nil
+	}
+
+	output, err := p.run(ctx, poolsListCommand)
+	if err != nil {
+		m.setField("pools", false)
+		return fmt.Errorf("pools list: %w", err)
+	}
+	pools, err := parsePoolsList(output)
+	if err != nil {
+		m.setField("pools", false)
+		return fmt.Errorf("pools list: %w", err)
+	}
+
+	var errs []error
+	for i := range pools {
+		pool := &pools[i]
+		if strings.HasPrefix(pool.Version, singlePoolVersion) {
+			continue
+		}
+		output, err := p.run(ctx, poolDataCommand+" "+pool.Address)
+		if err == nil {
+			err = parsePoolData(output, pool)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pool data of %s: %w", pool.Address, err))
+		}
+	}
+
+	m.Pools = pools
+	m.setField("pools", len(errs) == 0)
+	return errors.Join(errs...)
+}
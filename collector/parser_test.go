@@ -1,6 +1,9 @@
 package collector
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -75,25 +78,77 @@ Version validator: 1bef6df (master)
 MyTonCtrl> Bye.
 `,
 			want: LiteServerMetrics{
-				NetworkName:                    "testnet",
-				OnlineValidators:               23,
-				AllValidators:                  26,
-				NumberOfShardchains:            4,
-				NewOffers:                      1,
-				AllOffers:                      11,
-				NewComplaints:                  2,
-				AllComplaints:                  22,
-				ElectionStatus:                 "open",
-				AdnlAddress:                    "A56F2F60C9309BA2767EF3737A57B4CA1EB4DE66EE288F84ECC615B3EE6C8C81",
-				MytoncoreStatus:                "working",
-				MytoncoreUptimeSeconds:         (16 * 24 * time.Hour).Seconds(),
-				LocalValidatorStatus:           "working",
-				LocalValidatorUptimeSeconds:    (16 * 24 * time.Hour).Seconds(),
-				LocalValidatorOutOfSyncSeconds: 3,
+				NetworkName:                      "testnet",
+				OnlineValidators:                 23,
+				AllValidators:                    26,
+				NumberOfShardchains:              4,
+				NewOffers:                        1,
+				AllOffers:                        11,
+				NewComplaints:                    2,
+				AllComplaints:                    22,
+				ElectionStatus:                   "open",
+				AdnlAddress:                      "A56F2F60C9309BA2767EF3737A57B4CA1EB4DE66EE288F84ECC615B3EE6C8C81",
+				PublicAdnlAddress:                "5FEEBBC14F9098F4D216524E9B4D5DA5C56944C792B3CD70FB7BAC25513B5C23",
+				MytoncoreStatus:                  "working",
+				MytoncoreUptimeSeconds:           (16 * 24 * time.Hour).Seconds(),
+				LocalValidatorStatus:             "working",
+				LocalValidatorUptimeSeconds:      (16 * 24 * time.Hour).Seconds(),
+				LocalValidatorOutOfSyncSeconds:   3,
+				MytoncoreUptimePrecision:         "day",
+				LocalValidatorUptimePrecision:    "day",
+				LocalValidatorOutOfSyncPrecision: "second",
 				LocalValidatorLastStateSerializationBlocks: 2,
 				LocalValidatorDatabaseSizeGB:               27.31,
+				LocalValidatorDatabaseSizeBytes:            27.31e9,
+				LocalValidatorDatabaseDiskUsageRatio:       0.82,
 				VersionMytonctrl:                           "a467af5 (master)",
 				VersionValidator:                           "1bef6df (master)",
+				CPUCount:                                   16,
+				LoadAverage1m:                              0.48,
+				LoadAverage5m:                              0.47,
+				LoadAverage15m:                             0.44,
+				NetworkLoadAverage1m:                       25.39,
+				NetworkLoadAverage5m:                       24.22,
+				NetworkLoadAverage15m:                      24.79,
+				MemoryRAMUsedGB:                            17.31,
+				MemoryRAMUsagePercent:                      14.0,
+				MemorySwapUsedGB:                           0,
+				MemorySwapUsagePercent:                     0,
+				DisksLoad: []DiskLoad{
+					{Device: "nvme0n1", ThroughputMBs: 0.18, UtilizationPercent: 0.15},
+					{Device: "nvme1n1", ThroughputMBs: 0, UtilizationPercent: 0},
+					{Device: "nvme2n1", ThroughputMBs: 0.83, UtilizationPercent: 10.08},
+				},
+				ParsedFields: map[string]bool{
+					"network_name":                        true,
+					"online_validators":                   true,
+					"all_validators":                      true,
+					"number_of_shardchains":               true,
+					"new_offers":                          true,
+					"all_offers":                          true,
+					"new_complaints":                      true,
+					"all_complaints":                      true,
+					"election_status":                     true,
+					"adnl_address":                        true,
+					"public_adnl_address":                 true,
+					"cpu_count":                           true,
+					"load_average":                        true,
+					"network_load_average":                true,
+					"memory_ram":                          true,
+					"memory_swap":                         true,
+					"disks_load":                          true,
+					"mytoncore_status":                    true,
+					"mytoncore_uptime_seconds":            true,
+					"local_validator_status":              true,
+					"local_validator_uptime_seconds":      true,
+					"local_validator_out_of_sync_seconds": true,
+					"local_validator_last_state_serialization_blocks": true,
+					"local_validator_database_size_gb":                true,
+					"local_validator_database_size_bytes":             true,
+					"local_validator_database_disk_usage_ratio":       true,
+					"version_mytonctrl":                               true,
+					"version_validator":                               true,
+				},
 			},
 			whantErr: false,
 		},
@@ -146,41 +201,296 @@ End of elections: 24.09.2024 06:16:55 UTC
 Beginning of the next elections: 24.09.2024 07:39:55 UTC
 `,
 			want: LiteServerMetrics{
-				NetworkName:                    "testnet",
-				OnlineValidators:               23,
-				AllValidators:                  24,
-				NumberOfShardchains:            4,
-				NewOffers:                      0,
-				AllOffers:                      0,
-				NewComplaints:                  0,
-				AllComplaints:                  0,
-				ElectionStatus:                 "closed",
-				ValidatorIndex:                 -1,
-				AdnlAddress:                    "D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932",
-				WalletAddress:                  "kf_1160NJDnTqgz9AJ6p7EweUP_15xaZw1PK4WPcd48tDbQ1",
-				WalletBalance:                  95290.938201014,
-				MytoncoreStatus:                "working",
-				MytoncoreUptimeSeconds:         (14 * 24 * time.Hour).Seconds(),
-				LocalValidatorStatus:           "working",
-				LocalValidatorUptimeSeconds:    (16 * 24 * time.Hour).Seconds(),
-				LocalValidatorOutOfSyncSeconds: 3,
+				NetworkName:                         "testnet",
+				OnlineValidators:                    23,
+				AllValidators:                       24,
+				NumberOfShardchains:                 4,
+				NewOffers:                           0,
+				AllOffers:                           0,
+				NewComplaints:                       0,
+				AllComplaints:                       0,
+				ElectionStatus:                      "closed",
+				ValidatorIndex:                      -1,
+				AdnlAddress:                         "D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932",
+				PublicAdnlAddress:                   "BFE38D4B6B7CAB1FB4396068590C26857A400F93D2F4693208D181B7279B0348",
+				WalletAddress:                       "kf_1160NJDnTqgz9AJ6p7EweUP_15xaZw1PK4WPcd48tDbQ1",
+				WalletBalance:                       95290.938201014,
+				LocalValidatorWalletBalanceNanotons: 95290938201014,
+				MytoncoreStatus:                     "working",
+				MytoncoreUptimeSeconds:              (14 * 24 * time.Hour).Seconds(),
+				LocalValidatorStatus:                "working",
+				LocalValidatorUptimeSeconds:         (16 * 24 * time.Hour).Seconds(),
+				LocalValidatorOutOfSyncSeconds:      3,
+				MytoncoreUptimePrecision:            "day",
+				LocalValidatorUptimePrecision:       "day",
+				LocalValidatorOutOfSyncPrecision:    "second",
 				LocalValidatorLastStateSerializationBlocks: 3,
 				LocalValidatorDatabaseSizeGB:               25.89,
+				LocalValidatorDatabaseSizeBytes:            25.89e9,
+				LocalValidatorDatabaseDiskUsageRatio:       0.024,
 				VersionMytonctrl:                           "74536b (master)",
 				VersionValidator:                           "0c21ce2 (master)",
-				ConfiguratorAddress:                        "-1:5555555555555555555555555555555555555555555555555555555555555555",
-				ElectorAddress:                             "-1:3333333333333333333333333333333333333333333333333333333333333333",
-				ValidationPeriodSeconds:                    7200,
-				DurationOfElectionsSeconds:                 2400,
-				HoldPeriodSeconds:                          900,
-				MinimumStakeTONs:                           10000,
-				MaximumStakeTONs:                           5000000,
-				NetworkLaunchedTimestamp:                   float64(time.Date(2019, 11, 15, 12, 44, 14, 0, time.UTC).Unix()),
-				StartValidationCycleTimestamp:              float64(time.Date(2024, 9, 24, 6, 19, 55, 0, time.UTC).Unix()),
-				EndValidationCycleTimestamp:                float64(time.Date(2024, 9, 24, 8, 19, 55, 0, time.UTC).Unix()),
-				StartElectionsTimestamp:                    float64(time.Date(2024, 9, 24, 5, 39, 55, 0, time.UTC).Unix()),
-				EndElectionsTimestamp:                      float64(time.Date(2024, 9, 24, 6, 16, 55, 0, time.UTC).Unix()),
-				BeginNextElectionsTimestamp:                float64(time.Date(2024, 9, 24, 7, 39, 55, 0, time.UTC).Unix()),
+				CPUCount:                                   16,
+				LoadAverage1m:                              0.47,
+				LoadAverage5m:                              0.4,
+				LoadAverage15m:                             0.37,
+				NetworkLoadAverage1m:                       24.61,
+				NetworkLoadAverage5m:                       23.6,
+				NetworkLoadAverage15m:                      23.59,
+				MemoryRAMUsedGB:                            62.17,
+				MemoryRAMUsagePercent:                      47.5,
+				MemorySwapUsedGB:                           0,
+				MemorySwapUsagePercent:                     0.2,
+				DisksLoad: []DiskLoad{
+					{Device: "sr0", ThroughputMBs: 0, UtilizationPercent: 0},
+					{Device: "sr1", ThroughputMBs: 0, UtilizationPercent: 0},
+					{Device: "sr2", ThroughputMBs: 0, UtilizationPercent: 0},
+					{Device: "vda", ThroughputMBs: 0.18, UtilizationPercent: 0.47},
+					{Device: "vdb", ThroughputMBs: 0, UtilizationPercent: 0},
+					{Device: "vdc", ThroughputMBs: 0.54, UtilizationPercent: 4.28},
+				},
+				ConfiguratorAddress:           "-1:5555555555555555555555555555555555555555555555555555555555555555",
+				ElectorAddress:                "-1:3333333333333333333333333333333333333333333333333333333333333333",
+				ValidationPeriodSeconds:       7200,
+				DurationOfElectionsSeconds:    2400,
+				ElectionsStartBeforeSeconds:   2400,
+				ElectionsEndBeforeSeconds:     180,
+				HoldPeriodSeconds:             900,
+				MinimumStakeTONs:              10000,
+				MaximumStakeTONs:              5000000,
+				NetworkLaunchedTimestamp:      float64(time.Date(2019, 11, 15, 12, 44, 14, 0, time.UTC).Unix()),
+				StartValidationCycleTimestamp: float64(time.Date(2024, 9, 24, 6, 19, 55, 0, time.UTC).Unix()),
+				EndValidationCycleTimestamp:   float64(time.Date(2024, 9, 24, 8, 19, 55, 0, time.UTC).Unix()),
+				StartElectionsTimestamp:       float64(time.Date(2024, 9, 24, 5, 39, 55, 0, time.UTC).Unix()),
+				EndElectionsTimestamp:         float64(time.Date(2024, 9, 24, 6, 16, 55, 0, time.UTC).Unix()),
+				BeginNextElectionsTimestamp:   float64(time.Date(2024, 9, 24, 7, 39, 55, 0, time.UTC).Unix()),
+				ParsedFields: map[string]bool{
+					"network_name":          true,
+					"online_validators":     true,
+					"all_validators":        true,
+					"number_of_shardchains": true,
+					"new_offers":            true,
+					"all_offers":            true,
+					"new_complaints":        true,
+					"all_complaints":        true,
+					"election_status":       true,
+					"active_election_id":    true,
+					"validator_index":       true,
+					"adnl_address":          true,
+					"public_adnl_address":   true,
+					"wallet_address":        true,
+					"wallet_balance":        true,
+					"local_validator_wallet_balance_nanotons": true,
+					"cpu_count":                           true,
+					"load_average":                        true,
+					"network_load_average":                true,
+					"memory_ram":                          true,
+					"memory_swap":                         true,
+					"disks_load":                          true,
+					"mytoncore_status":                    true,
+					"mytoncore_uptime_seconds":            true,
+					"local_validator_status":              true,
+					"local_validator_uptime_seconds":      true,
+					"local_validator_out_of_sync_seconds": true,
+					"local_validator_last_state_serialization_blocks": true,
+					"local_validator_database_size_gb":                true,
+					"local_validator_database_size_bytes":             true,
+					"local_validator_database_disk_usage_ratio":       true,
+					"version_mytonctrl":                               true,
+					"version_validator":                               true,
+					"configurator_address":                            true,
+					"elector_address":                                 true,
+					"validation_period_seconds":                       true,
+					"duration_of_elections_seconds":                   true,
+					"elections_start_before_seconds":                  true,
+					"elections_end_before_seconds":                    true,
+					"hold_period_seconds":                             true,
+					"minimum_stake_tons":                              true,
+					"maximum_stake_tons":                              true,
+					"network_launched_timestamp":                      true,
+					"start_validation_cycle_timestamp":                true,
+					"end_validation_cycle_timestamp":                  true,
+					"start_elections_timestamp":                       true,
+					"end_elections_timestamp":                         true,
+					"begin_next_elections_timestamp":                  true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "print output",
+			input: `{
+  "network_name": "mainnet",
+  "online_validators": 381,
+  "all_validators": 384,
+  "election_status": "closed",
+  "validator_index": 12,
+  "wallet_balance": "lots",
+  "local_validator_wallet_balance_nanotons": "95290938201014",
+  "load_average_1m": 0.48,
+  "load_average_5m": 0.47,
+  "load_average_15m": 0.44,
+  "disks_load": [{"device": "nvme0n1", "throughput_mbs": 0.18, "utilization_percent": 0.15}],
+  "mytoncore_uptime_seconds": null,
+  "unknown_field": true
+}
+`,
+			want: LiteServerMetrics{
+				NetworkName:                         "mainnet",
+				OnlineValidators:                    381,
+				AllValidators:                       384,
+				ElectionStatus:                      "closed",
+				ValidatorIndex:                      12,
+				LocalValidatorWalletBalanceNanotons: 95290938201014,
+				LoadAverage1m:                       0.48,
+				LoadAverage5m:                       0.47,
+				LoadAverage15m:                      0.44,
+				DisksLoad: []DiskLoad{
+					{Device: "nvme0n1", ThroughputMBs: 0.18, UtilizationPercent: 0.15},
+				},
+				ParsedFields: map[string]bool{
+					"network_name":      true,
+					"online_validators": true,
+					"all_validators":    true,
+					"election_status":   true,
+					"validator_index":   true,
+					"wallet_balance":    false,
+					"local_validator_wallet_balance_nanotons": true,
+					"load_average": true,
+					"disks_load":   true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "json precision without its duration",
+			input: `{
+  "network_name": "mainnet",
+  "mytoncore_uptime_precision": "minute",
+  "local_validator_uptime_seconds": 3600,
+  "local_validator_uptime_precision": "hour",
+  "local_validator_out_of_sync_precision": 1
+}`,
+			want: LiteServerMetrics{
+				NetworkName:                   "mainnet",
+				MytoncoreUptimePrecision:      "minute",
+				LocalValidatorUptimeSeconds:   3600,
+				LocalValidatorUptimePrecision: "hour",
+				ParsedFields: map[string]bool{
+					"network_name":                        true,
+					"local_validator_uptime_seconds":      true,
+					"local_validator_out_of_sync_seconds": false,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "invalid json falls back to text",
+			input: `
+{"network_name": "mainnet",
+Network name: testnet
+`,
+			want: LiteServerMetrics{
+				NetworkName:  "testnet",
+				ParsedFields: map[string]bool{"network_name": true},
+			},
+			whantErr: false,
+		},
+		{
+			name: "validation periods config",
+			input: `
+Validation period: 65536, Duration of elections: 32768-8192, Hold period: 32768
+`,
+			want: LiteServerMetrics{
+				ValidationPeriodSeconds:     65536,
+				DurationOfElectionsSeconds:  32768,
+				ElectionsStartBeforeSeconds: 32768,
+				ElectionsEndBeforeSeconds:   8192,
+				HoldPeriodSeconds:           32768,
+				ParsedFields: map[string]bool{
+					"validation_period_seconds":      true,
+					"duration_of_elections_seconds":  true,
+					"elections_start_before_seconds": true,
+					"elections_end_before_seconds":   true,
+					"hold_period_seconds":            true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "database size without usage",
+			input: `
+Local validator database size: 25.89 Gb
+`,
+			want: LiteServerMetrics{
+				LocalValidatorDatabaseSizeBytes: 25.89e9,
+				LocalValidatorDatabaseSizeGB:    25.89,
+				ParsedFields: map[string]bool{
+					"local_validator_database_size_bytes": true,
+					"local_validator_database_size_gb":    true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "elections duration without end",
+			input: `
+Validation period: 7200, Duration of elections: 2400, Hold period: 900
+`,
+			want: LiteServerMetrics{
+				ValidationPeriodSeconds:     7200,
+				DurationOfElectionsSeconds:  2400,
+				ElectionsStartBeforeSeconds: 2400,
+				HoldPeriodSeconds:           900,
+				ParsedFields: map[string]bool{
+					"validation_period_seconds":      true,
+					"duration_of_elections_seconds":  true,
+					"elections_start_before_seconds": true,
+					"hold_period_seconds":            true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "stakes config",
+			input: `
+Minimum stake: 300000.0, Maximum stake: 10000000.0
+`,
+			want: LiteServerMetrics{
+				MinimumStakeTONs: 300000,
+				MaximumStakeTONs: 10000000,
+				ParsedFields: map[string]bool{
+					"minimum_stake_tons": true,
+					"maximum_stake_tons": true,
+				},
+			},
+			whantErr: false,
+		},
+		{
+			name: "malformed values",
+			input: `
+Number of validators: many
+Validator index: -1
+Mytoncore status: working
+Local validator status: working, 16 days
+Start of elections: yesterday
+`,
+			want: LiteServerMetrics{
+				ValidatorIndex:                -1,
+				MytoncoreStatus:               "working",
+				LocalValidatorStatus:          "working",
+				LocalValidatorUptimeSeconds:   (16 * 24 * time.Hour).Seconds(),
+				LocalValidatorUptimePrecision: "day",
+				ParsedFields: map[string]bool{
+					"online_validators":              false,
+					"all_validators":                 false,
+					"validator_index":                true,
+					"mytoncore_status":               true,
+					"mytoncore_uptime_seconds":       false,
+					"local_validator_status":         true,
+					"local_validator_uptime_seconds": true,
+					"start_elections_timestamp":      false,
+				},
 			},
 			whantErr: false,
 		},
@@ -188,7 +498,7 @@ Beginning of the next elections: 24.09.2024 07:39:55 UTC
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parser := NewParser()
+			parser := NewParser(nil, 0, ParserOptions{})
 			got, err := parser.ParseOutput(tt.input)
 			if (err != nil) != tt.whantErr {
 				t.Errorf("Parser.ParseOutput() error = %v, wantErr %v", err, tt.whantErr)
@@ -200,3 +510,718 @@ Beginning of the next elections: 24.09.2024 07:39:55 UTC
 		})
 	}
 }
+
+// runnerSource returns the status output and the output of other mytonctrl commands.
+type runnerSource struct {
+	status   string
+	commands map[string]string
+}
+
+func (s runnerSource) Fetch(_ context.Context) (string, error) {
+	return s.status, nil
+}
+
+func (s runnerSource) Run(_ context.Context, command string) (string, error) {
+	output, ok := s.commands[command]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown command %q", ErrExitCode, command)
+	}
+	return output, nil
+}
+
+func TestParser_ValidatorList(t *testing.T) {
+	validatorList := `
+Welcome to the console. Enter 'help' to display the help menu.
+[debug]   16.10.2024, 16:11:48.608 (UTC)  <MainThread>  start GetValidatorsList function
+MyTonCtrl> [
+  {"adnlAddr": "AA00", "pubkey": "PK00", "walletAddr": "Ef00", "online": true, "weight": 0.4, "stake": 1000000,
+   "efficiency": 99.1, "master_blocks_created": 10, "master_blocks_expected": 10.2,
+   "work_blocks_created": 120, "work_blocks_expected": 118.5},
+  {"adnlAddr": "AA11", "pubkey": "PK11", "walletAddr": "Ef11", "online": true, "weight": 0.35, "stake": 875000.5,
+   "efficiency": 87.5, "master_blocks_created": 7, "master_blocks_expected": 8,
+   "work_blocks_created": 100, "work_blocks_expected": 114.25},
+  {"adnlAddr": "AA22", "efficiency": null}
+]
+MyTonCtrl> Bye.
+`
+
+	tests := []struct {
+		name    string
+		status  string
+		options ParserOptions
+		want    LiteServerMetrics
+	}{
+		{
+			name:    "local validator",
+			status:  "Validator index: 1\nADNL address of local validator: AA11\n",
+			options: ParserOptions{ValidatorList: true},
+			want: LiteServerMetrics{
+				ValidatorIndex:           1,
+				AdnlAddress:              "AA11",
+				ValidatorEfficiencyRatio: 0.875,
+				MasterBlocksCreated:      7,
+				MasterBlocksExpected:     8,
+				WorkBlocksCreated:        100,
+				WorkBlocksExpected:       114.25,
+				ParsedFields: map[string]bool{
+					"validator_index":            true,
+					"adnl_address":               true,
+					"validator_list":             true,
+					"validator_efficiency_ratio": true,
+					"blocks_created":             true,
+					"blocks_expected":            true,
+				},
+			},
+		},
+		{
+			name:    "efficiency not computed",
+			status:  "Validator index: 2\n",
+			options: ParserOptions{ValidatorList: true},
+			want: LiteServerMetrics{
+				ValidatorIndex: 2,
+				ParsedFields:   map[string]bool{"validator_index": true, "validator_list": true},
+			},
+		},
+		{
+			name:    "local validator mismatch",
+			status:  "Validator index: 0\nADNL address of local validator: AA11\n",
+			options: ParserOptions{ValidatorList: true},
+			want: LiteServerMetrics{
+				AdnlAddress:  "AA11",
+				ParsedFields: map[string]bool{"validator_index": true, "adnl_address": true, "validator_list": false},
+			},
+		},
+		{
+			name:    "not a validator",
+			status:  "Validator index: -1\n",
+			options: ParserOptions{ValidatorList: true},
+			want: LiteServerMetrics{
+				ValidatorIndex: -1,
+				ParsedFields:   map[string]bool{"validator_index": true},
+			},
+		},
+		{
+			name:    "validator set",
+			status:  "Validator index: -1\n",
+			options: ParserOptions{ValidatorSet: true, ValidatorSetLimit: 2},
+			want: LiteServerMetrics{
+				ValidatorIndex: -1,
+				Validators: []Validator{
+					{AdnlAddress: "AA00", PublicKey: "PK00", WalletAddress: "Ef00", Online: true, Weight: 0.4, StakeTONs: 1000000, EfficiencyRatio: ptr(0.991)},
+					{AdnlAddress: "AA11", PublicKey: "PK11", WalletAddress: "Ef11", Online: true, Weight: 0.35, StakeTONs: 875000.5, EfficiencyRatio: ptr(0.875)},
+				},
+				ValidatorSetDropped: 1,
+				ParsedFields:        map[string]bool{"validator_index": true, "validator_list": true, "validators": true},
+			},
+		},
+		{
+			name:   "disabled",
+			status: "Validator index: 1\n",
+			want: LiteServerMetrics{
+				ValidatorIndex: 1,
+				ParsedFields:   map[string]bool{"validator_index": true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := runnerSource{status: tt.status, commands: map[string]string{validatorListCommand: validatorList}}
+			got, err := NewParser(source, 0, tt.options).Parse(context.Background())
+			if err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, *got); diff != "" {
+				t.Errorf("Parser.Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestParser_OffersComplaints(t *testing.T) {
+	source := runnerSource{
+		status: "ADNL address of local validator: AA11\n",
+		commands: map[string]string{
+			offersListCommand: `
+MyTonCtrl> [
+  {"hash": "H1", "config": {"id": 17}, "votedValidators": [0, 4, 7], "approvedPercent": 42.5, "isPassed": false},
+  {"hash": "H2", "config": {"id": 34}, "votedValidators": [], "approvedPercent": 80, "isPassed": true}
+]
+`,
+			complaintsListCommand: `
+MyTonCtrl> {
+  "P2": {"electionId": 1727156395, "adnl": "AA11", "suggestedFine": 101.5},
+  "P1": {"electionId": 1727156395, "pseudohash": "P1", "adnl": "BB22", "suggestedFine": 101}
+}
+`,
+		},
+	}
+
+	got, err := NewParser(source, 0, ParserOptions{OffersComplaints: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := LiteServerMetrics{
+		AdnlAddress: "AA11",
+		Offers: []Offer{
+			{Hash: "H1", ConfigParamID: 17, Votes: 3, ApprovedPercent: 42.5},
+		},
+		Complaints: []Complaint{
+			{Hash: "P1", ElectionID: "1727156395", TargetAdnl: "BB22", FineTONs: 101},
+			{Hash: "P2", ElectionID: "1727156395", TargetAdnl: "AA11", FineTONs: 101.5, TargetsLocal: true},
+		},
+		ComplaintsAgainstLocalValidator: 1,
+		ParsedFields: map[string]bool{
+			"adnl_address":                       true,
+			"offers_complaints":                  true,
+			"offers":                             true,
+			"complaints":                         true,
+			"complaints_against_local_validator": true,
+		},
+	}
+	if diff := cmp.Diff(want, *got); diff != "" {
+		t.Errorf("Parser.Parse() mismatch (-want +got):\n%s", diff)
+	}
+
+	delete(source.commands, complaintsListCommand)
+	got, err = NewParser(source, 0, ParserOptions{OffersComplaints: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if ok, found := got.ParsedFields["offers_complaints"]; ok || !found {
+		t.Errorf("Parser.Parse() offers_complaints = %v, %v, want a field error", ok, found)
+	}
+}
+
+// electionEntries is 'el --json' output in the format of mytonctrl, whose
+// entries are keyed by ADNL address.
+const electionEntries = `
+Welcome to the console. Enter 'help' to display the help menu.
+MyTonCtrl> {
+  "D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932": {
+    "adnlAddr": "D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932",
+    "pubkey": "0F4FE1B2F0DB6C4D76C3C4F1A8C9A1A7C4D3C60C4E3F2D8A4A7C6B1C2D3E4F50",
+    "stake": 350000.5,
+    "maxFactor": 3.0,
+    "walletAddr": "Ef_1160NJDnTqgz9AJ6p7EweUP_15xaZw1PK4WPcd48tDbQ1"
+  },
+  "8C2B1F06D3A64E5C0F5E8C4B6B1A6F2E0D7C8B9A1F2E3D4C5B6A7F8E9D0C1B2A": {
+    "adnlAddr": "8C2B1F06D3A64E5C0F5E8C4B6B1A6F2E0D7C8B9A1F2E3D4C5B6A7F8E9D0C1B2A",
+    "pubkey": "5A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F9",
+    "stake": 1250000.0,
+    "maxFactor": 2.5,
+    "walletAddr": "Ef9rkkVM0xhwvadmFTXhK0oKz1c2vN0g3XNHcvo9EaJNqzPl"
+  }
+}
+MyTonCtrl> Bye.
+`
+
+// electionStatus is mytonctrl status output of a validator, with the election
+// status left to format.
+const electionStatus = `
+===[ TON network status ]===
+Network name: testnet
+Number of validators: 23(24)
+Number of shardchains: 4
+Number of offers: 0(0)
+Number of complaints: 0(0)
+Election status: %s
+===[ Node status ]===
+Validator index: 3
+ADNL address of local validator: D70B2BD8F394EF44DE89B6614DD0D91C672FC28C8A4B7D8C918E2C106A9DF932
+Local validator wallet address: kf_1160NJDnTqgz9AJ6p7EweUP_15xaZw1PK4WPcd48tDbQ1
+Mytoncore status: working, 14 days
+Local validator status: working, 16 days
+===[ TON network configuration ]===
+Configurator address: -1:5555555555555555555555555555555555555555555555555555555555555555
+Elector address: -1:3333333333333333333333333333333333333333333333333333333333333333
+Validation period: 7200, Duration of elections: 2400-180, Hold period: 900
+Minimum stake: 10000.0, Maximum stake: 5000000.0
+===[ TON timestamps ]===
+TON network was launched: 15.11.2019 12:44:14 UTC
+Start of the validation cycle: 24.09.2024 06:19:55 UTC
+End of the validation cycle: 24.09.2024 08:19:55 UTC
+Start of elections: 24.09.2024 05:39:55 UTC
+End of elections: 24.09.2024 06:16:55 UTC
+Beginning of the next elections: 24.09.2024 07:39:55 UTC
+`
+
+func TestParser_ElectionEntries(t *testing.T) {
+	// The open election elects the cycle starting at the end of the current one.
+	electionID := float64(time.Date(2024, 9, 24, 8, 19, 55, 0, time.UTC).Unix())
+	held := time.Unix(int64(electionID), 0).Add(7200 * time.Second).Add(900 * time.Second)
+	openWithStake := runnerSource{
+		status:   fmt.Sprintf(electionStatus, "open"),
+		commands: map[string]string{electionEntriesCommand: electionEntries},
+	}
+
+	tests := []struct {
+		name string
+		// staked runs a scrape of the open election with a stake first.
+		staked     bool
+		source     runnerSource
+		now        time.Time
+		wantStake  float64
+		wantLatest float64
+		wantState  string
+		wantError  bool
+	}{
+		{
+			name:       "stake in the open election",
+			source:     openWithStake,
+			now:        time.Date(2024, 9, 24, 8, 0, 0, 0, time.UTC),
+			wantStake:  350000.5,
+			wantLatest: electionID,
+			wantState:  stakeStateFrozen,
+		},
+		{
+			name: "open election without a stake",
+			source: runnerSource{
+				status:   fmt.Sprintf(electionStatus, "open"),
+				commands: map[string]string{electionEntriesCommand: "MyTonCtrl> {}\n"},
+			},
+			now: time.Date(2024, 9, 24, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "elections closed while the stake is held",
+			staked: true,
+			source: runnerSource{
+				status:   fmt.Sprintf(electionStatus, "closed"),
+				commands: map[string]string{electionEntriesCommand: "MyTonCtrl> {}\n"},
+			},
+			now:        held.Add(-time.Second),
+			wantLatest: electionID,
+			wantState:  stakeStateFrozen,
+		},
+		{
+			name:   "hold period over",
+			staked: true,
+			source: runnerSource{
+				status:   fmt.Sprintf(electionStatus, "closed"),
+				commands: map[string]string{electionEntriesCommand: "MyTonCtrl> {}\n"},
+			},
+			now:        held,
+			wantLatest: electionID,
+			wantState:  stakeStateReturned,
+		},
+		{
+			name: "elections closed without a stake seen",
+			source: runnerSource{
+				status:   fmt.Sprintf(electionStatus, "closed"),
+				commands: map[string]string{electionEntriesCommand: "MyTonCtrl> {}\n"},
+			},
+			now: held,
+		},
+		{
+			name:      "el fails",
+			staked:    true,
+			source:    runnerSource{status: fmt.Sprintf(electionStatus, "closed")},
+			now:       held,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewParser(openWithStake, 0, ParserOptions{ElectionEntries: true})
+			parser.now = func() time.Time { return tt.now }
+			if tt.staked {
+				if _, err := parser.Parse(context.Background()); err != nil {
+					t.Fatalf("Parser.Parse() error = %v", err)
+				}
+			}
+
+			parser.source = tt.source
+			got, err := parser.Parse(context.Background())
+			if err != nil {
+				t.Fatalf("Parser.Parse() error = %v", err)
+			}
+
+			if tt.wantError {
+				if ok, found := got.ParsedFields["local_validator_stake_tons"]; ok || !found {
+					t.Errorf("Parser.Parse() local_validator_stake_tons = %v, %v, want a field error", ok, found)
+				}
+				return
+			}
+			if !got.Parsed("local_validator_stake_tons") || got.LocalValidatorStakeTONs != tt.wantStake {
+				t.Errorf("Parser.Parse() LocalValidatorStakeTONs = %v, want %v", got.LocalValidatorStakeTONs, tt.wantStake)
+			}
+			if got.LatestParticipatedElectionID != tt.wantLatest {
+				t.Errorf("Parser.Parse() LatestParticipatedElectionID = %v, want %v", got.LatestParticipatedElectionID, tt.wantLatest)
+			}
+			if got.LocalValidatorStakeState != tt.wantState || got.Parsed("local_validator_stake_state") != (tt.wantState != "") {
+				t.Errorf("Parser.Parse() LocalValidatorStakeState = %q, want %q", got.LocalValidatorStakeState, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestParser_ActiveElectionID(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   float64
+		wantOK bool
+	}{
+		{
+			name:   "open",
+			status: fmt.Sprintf(electionStatus, "open"),
+			want:   float64(time.Date(2024, 9, 24, 8, 19, 55, 0, time.UTC).Unix()),
+			wantOK: true,
+		},
+		{
+			name:   "closed",
+			status: fmt.Sprintf(electionStatus, "closed"),
+			wantOK: true,
+		},
+		{
+			name:   "open without timestamps",
+			status: "Election status: open\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&Parser{}).ParseOutput(tt.status)
+			if err != nil {
+				t.Fatalf("ParseOutput() error = %v", err)
+			}
+			if got.ActiveElectionID != tt.want || got.Parsed("active_election_id") != tt.wantOK {
+				t.Errorf("ParseOutput() ActiveElectionID = %v, %v, want %v, %v",
+					got.ActiveElectionID, got.Parsed("active_election_id"), tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParser_Accounts(t *testing.T) {
+	calls := make(map[string]int)
+	source := countingRunnerSource{
+		runnerSource: runnerSource{
+			status: "Network name: mainnet\n",
+			commands: map[string]string{
+				"vas EQPool": `
+MyTonCtrl> Address  Status  Balance          Version
+EQPool   active  1000000.123456789  nominator-pool-v1
+
+Code hash
+8f1b1a...
+`,
+				"vas EQOwner": "Address  Status  Balance  Version\nEQOwner  uninit  0.5  None\n",
+			},
+		},
+		calls: calls,
+	}
+	now := time.Date(2024, 9, 24, 7, 0, 0, 0, time.UTC)
+	parser := NewParser(source, 0, ParserOptions{
+		Accounts: []WatchedAccount{
+			{Address: "EQPool", Alias: "pool"},
+			{Address: "EQOwner", Alias: "owner"},
+			{Address: "EQMissing"},
+		},
+		AccountsInterval: 5 * time.Minute,
+	})
+	parser.now = func() time.Time { return now }
+
+	wantAccounts := []AccountStatus{
+		{Address: "EQPool", Alias: "pool", State: "active", BalanceNanotons: 1000000123456789},
+		{Address: "EQOwner", Alias: "owner", State: "uninit", BalanceNanotons: 500000000},
+	}
+	for _, step := range []struct {
+		after      time.Duration
+		wantCalls  int
+		wantFailed bool
+	}{
+		{after: 0, wantCalls: 1, wantFailed: true},
+		// Within the interval every account is served from its last query.
+		{after: time.Minute, wantCalls: 1, wantFailed: false},
+		{after: 5 * time.Minute, wantCalls: 2, wantFailed: true},
+	} {
+		now = now.Add(step.after)
+		got, err := parser.Parse(context.Background())
+		if err != nil {
+			t.Fatalf("Parser.Parse() error = %v", err)
+		}
+		if diff := cmp.Diff(wantAccounts, got.Accounts); diff != "" {
+			t.Errorf("Parser.Parse() accounts mismatch (-want +got):\n%s", diff)
+		}
+		if ok := got.ParsedFields["accounts"]; ok == step.wantFailed {
+			t.Errorf("Parser.Parse() accounts parsed = %v, want %v", ok, !step.wantFailed)
+		}
+		for _, command := range []string{"vas EQPool", "vas EQOwner", "vas EQMissing"} {
+			if calls[command] != step.wantCalls {
+				t.Errorf("%q ran %d times, want %d", command, calls[command], step.wantCalls)
+			}
+		}
+	}
+}
+
+func TestParseAccountStatus(t *testing.T) {
+	for _, output := range []string{
+		"",
+		"Address  Status  Balance  Version\n",
+		"Address  Status  Balance  Version\nEQPool  active\n",
+		"Address  Status  Balance  Version\nEQPool  active  lots  v3r2\n",
+	} {
+		if _, _, err := parseAccountStatus(output); err == nil {
+			t.Errorf("parseAccountStatus(%q) succeeded, want an error", output)
+		}
+	}
+}
+
+// countingRunnerSource is a runnerSource counting the runs of every command.
+type countingRunnerSource struct {
+	runnerSource
+	calls map[string]int
+}
+
+func (s countingRunnerSource) Run(ctx context.Context, command string) (string, error) {
+	s.calls[command]++
+	return s.runnerSource.Run(ctx, command)
+}
+
+func TestParser_Pools(t *testing.T) {
+	source := runnerSource{
+		status: "Network name: mainnet\n",
+		commands: map[string]string{
+			poolsListCommand: `
+MyTonCtrl> Name    Status  Balance      Version   Address
+pool1   active  1000.5       pool      EQPool1
+pool2   uninit  0.0          pool      EQPool2
+single  active  500000.25    spool_r2  EQSingle
+`,
+			poolDataCommand + " EQPool1": `
+MyTonCtrl> {
+  "state": 1,
+  "nominatorsCount": 3,
+  "stakeAmountSent": 900000.0,
+  "validatorAmount": 100000.0,
+  "config": {"validatorAddress": "-1:ab", "validatorRewardShare": 4000, "validatorRewardSharePercent": 40.0},
+  "nominators": [
+    {"address": "EQN1", "amount": 300000.0, "pendingDepositAmount": 10.5},
+    {"address": "EQN2", "amount": 300000.0, "pendingDepositAmount": 0}
+  ],
+  "withdrawRequests": {"EQN1": true}
+}
+`,
+			poolDataCommand + " EQPool2": `{"state": 0, "nominatorsCount": 0, "config": {"validatorRewardShare": 0}, "nominators": "x{}", "withdrawRequests": null}`,
+		},
+	}
+
+	got, err := NewParser(source, 0, ParserOptions{Pools: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+
+	want := []Pool{
+		{
+			Name:                      "pool1",
+			Address:                   "EQPool1",
+			Version:                   "pool",
+			State:                     poolStateActive,
+			BalanceTONs:               1000.5,
+			Nominators:                ptr(3.0),
+			ValidatorRewardShareRatio: ptr(0.4),
+			PendingDepositsTONs:       ptr(10.5),
+			PendingWithdrawals:        ptr(1.0),
+		},
+		{
+			Name:                      "pool2",
+			Address:                   "EQPool2",
+			Version:                   "pool",
+			State:                     poolStateStopped,
+			Nominators:                ptr(0.0),
+			ValidatorRewardShareRatio: ptr(0.0),
+			PendingWithdrawals:        ptr(0.0),
+		},
+		{Name: "single", Address: "EQSingle", Version: "spool_r2", State: poolStateActive, BalanceTONs: 500000.25},
+	}
+	if diff := cmp.Diff(want, got.Pools); diff != "" {
+		t.Errorf("Parser.Parse() pools mismatch (-want +got):\n%s", diff)
+	}
+	if !got.Parsed("pools") {
+		t.Error("Parser.Parse() pools not parsed")
+	}
+
+	delete(source.commands, poolDataCommand+" EQPool2")
+	got, err = NewParser(source, 0, ParserOptions{Pools: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if ok, found := got.ParsedFields["pools"]; ok || !found {
+		t.Errorf("Parser.Parse() pools = %v, %v, want a field error", ok, found)
+	}
+	// The pools are still exported without the data that failed.
+	if len(got.Pools) != 3 || got.Pools[1].Nominators != nil {
+		t.Errorf("Parser.Parse() pools = %+v, want every pool without the data of EQPool2", got.Pools)
+	}
+
+	source.commands[poolsListCommand] = "MyTonCtrl> No data\n"
+	got, err = NewParser(source, 0, ParserOptions{Pools: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if len(got.Pools) != 0 || !got.Parsed("pools") {
+		t.Errorf("Parser.Parse() pools = %+v, %v, want none", got.Pools, got.Parsed("pools"))
+	}
+}
+
+func TestParser_Structured(t *testing.T) {
+	const status = "Number of validators: 23(26)\nNumber of offers: 1(4)\nNumber of complaints: 0(5)\n"
+
+	tests := []struct {
+		name     string
+		commands map[string]string
+		want     LiteServerMetrics
+		wantRuns int
+	}{
+		{
+			name: "supported",
+			commands: map[string]string{
+				validatorListCommand: `
+[debug]   16.10.2024, 16:11:48.608 (UTC)  <MainThread>  start GetValidatorsList function
+MyTonCtrl> [
+  {"adnlAddr": "AA00", "pubkey": "PK00", "walletAddr": "Ef00", "online": true, "stake": 1000000},
+  {"adnlAddr": "AA11", "pubkey": "PK11", "walletAddr": "Ef11", "online": false, "stake": 875000.5},
+  {"adnlAddr": "AA22", "pubkey": "PK22", "walletAddr": "Ef22", "online": true, "stake": 500000}
+]
+MyTonCtrl> Bye.
+`,
+				offersListCommand: `
+MyTonCtrl> [
+  {"hash": "H1", "config": {"id": 17}, "votedValidators": [0, 4], "approvedPercent": 42.5, "isPassed": false},
+  {"hash": "H2", "config": {"id": 34}, "votedValidators": [], "approvedPercent": 80, "isPassed": true}
+]
+`,
+				complaintsListCommand: `
+MyTonCtrl> {
+  "P1": {"electionId": 1727156395, "pseudohash": "P1", "adnl": "BB22", "suggestedFine": 101}
+}
+`,
+			},
+			want: LiteServerMetrics{
+				OnlineValidators: 2,
+				AllValidators:    3,
+				NewOffers:        1,
+				AllOffers:        2,
+				AllComplaints:    1,
+				ParsedFields: map[string]bool{
+					"online_validators": true,
+					"all_validators":    true,
+					"new_offers":        true,
+					"all_offers":        true,
+					"new_complaints":    true,
+					"all_complaints":    true,
+				},
+			},
+			wantRuns: 3,
+		},
+		{
+			name: "unsupported",
+			commands: map[string]string{
+				validatorListCommand: "MyTonCtrl> Unknown command 'vl --json'\n",
+			},
+			want: LiteServerMetrics{
+				OnlineValidators: 23,
+				AllValidators:    26,
+				NewOffers:        1,
+				AllOffers:        4,
+				AllComplaints:    5,
+				ParsedFields: map[string]bool{
+					"online_validators": true,
+					"all_validators":    true,
+					"new_offers":        true,
+					"all_offers":        true,
+					"new_complaints":    true,
+					"all_complaints":    true,
+				},
+			},
+			wantRuns: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := countingRunnerSource{
+				runnerSource: runnerSource{status: status, commands: tt.commands},
+				calls:        make(map[string]int),
+			}
+			parser := NewParser(source, 0, ParserOptions{Structured: true})
+
+			// The first 'vl --json' output probes the build, later scrapes
+			// of an unsupported build do not run it again.
+			for i := 0; i < 3; i++ {
+				got, err := parser.Parse(context.Background())
+				if err != nil {
+					t.Fatalf("Parser.Parse() error = %v", err)
+				}
+				if diff := cmp.Diff(tt.want, *got); diff != "" {
+					t.Errorf("Parser.Parse() mismatch (-want +got):\n%s", diff)
+				}
+			}
+			if got := source.calls[validatorListCommand]; got != tt.wantRuns {
+				t.Errorf("'vl --json' ran %d times, want %d", got, tt.wantRuns)
+			}
+		})
+	}
+}
+
+// slowSource is a runnerSource taking delay to answer each command.
+type slowSource struct {
+	runnerSource
+	delay time.Duration
+}
+
+func (s slowSource) Fetch(ctx context.Context) (string, error) {
+	if err := s.wait(ctx); err != nil {
+		return "", err
+	}
+	return s.runnerSource.Fetch(ctx)
+}
+
+func (s slowSource) Run(ctx context.Context, command string) (string, error) {
+	if err := s.wait(ctx); err != nil {
+		return "", err
+	}
+	return s.runnerSource.Run(ctx, command)
+}
+
+func (s slowSource) wait(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+	}
+}
+
+func TestParser_TimeoutPerCommand(t *testing.T) {
+	source := slowSource{
+		runnerSource: runnerSource{
+			status:   "Validator index: 0\nADNL address of local validator: AA11\n",
+			commands: map[string]string{validatorListCommand: `[{"adnlAddr": "AA11", "efficiency": 87.5}]`},
+		},
+		delay: 150 * time.Millisecond,
+	}
+
+	// 'status' and 'vl' together take longer than the timeout, each fits in it.
+	got, err := NewParser(source, time.Second/4, ParserOptions{ValidatorList: true}).Parse(context.Background())
+	if err != nil {
+		t.Fatalf("Parser.Parse() error = %v", err)
+	}
+	if !got.Parsed("validator_list") || got.ValidatorEfficiencyRatio != 0.875 {
+		t.Errorf("Parser.Parse() validator_list = %v, efficiency %v, want 0.875", got.Parsed("validator_list"), got.ValidatorEfficiencyRatio)
+	}
+
+	// A status fetch over the timeout fails the scrape.
+	source.delay = time.Second / 2
+	_, err = NewParser(source, time.Second/4, ParserOptions{ValidatorList: true}).Parse(context.Background())
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Parser.Parse() error = %v, want %v", err, ErrTimeout)
+	}
+}
@@ -0,0 +1,174 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// validatorListCommand prints the current validator set as a JSON array.
+const validatorListCommand = "vl --json"
+
+// ParserOptions enables the optional mytonctrl command passes that Parse runs
+// after 'status'. They need a source implementing CommandRunner.
+type ParserOptions struct {
+	// Structured runs 'vl --json', 'ol --json' and 'cl --json' to read the
+	// validator, offer and complaint counts from JSON instead of the status
+	// text, when the mytonctrl build supports it.
+	Structured bool
+	// ValidatorList runs 'vl --json' to read the efficiency and the created
+	// blocks of the local validator. It only runs while the node is a validator.
+	ValidatorList bool
+	// ValidatorSet runs 'vl --json' to read every validator of the current set.
+	ValidatorSet bool
+	// ValidatorSetLimit caps the number of validators read by ValidatorSet,
+	// the ones with the lowest index are kept. Zero means no limit.
+	ValidatorSetLimit int
+	// PastStakesLimit caps the number of past elections whose stake is read
+	// from the mytoncore database, the latest ones are kept. Zero means no limit.
+	PastStakesLimit int
+	// OffersComplaints runs 'ol --json' and 'cl --json' to read the open offers
+	// and the complaints of the current round.
+	OffersComplaints bool
+	// ElectionEntries runs 'el --json' to read the stake of the local validator
+	// in the current election and the state of its latest stake.
+	ElectionEntries bool
+	// Accounts runs 'vas' for every watched account to read its balance and
+	// state, each at most once per AccountsInterval.
+	Accounts         []WatchedAccount
+	AccountsInterval time.Duration
+	// Pools runs 'pools_list' and 'get_pool_data' to read the nominator pools
+	// and single nominator pools of the node.
+	Pools bool
+}
+
+// validatorListEntry is a validator of the 'vl --json' output. Values that
+// mytoncore has not computed yet are null.
+type validatorListEntry struct {
+	AdnlAddr             string   `json:"adnlAddr"`
+	Pubkey               string   `json:"pubkey"`
+	WalletAddr           string   `json:"walletAddr"`
+	Online               bool     `json:"online"`
+	Weight               float64  `json:"weight"`
+	Stake                float64  `json:"stake"`
+	Efficiency           *float64 `json:"efficiency"`
+	MasterBlocksCreated  *float64 `json:"master_blocks_created"`
+	MasterBlocksExpected *float64 `json:"master_blocks_expected"`
+	WorkBlocksCreated    *float64 `json:"work_blocks_created"`
+	WorkBlocksExpected   *float64 `json:"work_blocks_expected"`
+}
+
+// parseValidatorList parses the 'vl --json' output.
+func parseValidatorList(output string) ([]validatorListEntry, error) {
+	data, ok := extractJSON(output, "[", "]")
+	if !ok {
+		return nil, errors.New("no validator list in output")
+	}
+
+	var validators []validatorListEntry
+	if err := json.Unmarshal([]byte(data), &validators); err != nil {
+		return nil, fmt.Errorf("invalid validator list: %w", err)
+	}
+
+	return validators, nil
+}
+
+// runValidatorList runs the validator list pass when enabled, recording a failure
+// as a "validator_list" field error so the status metrics are still exported.
+func (p *Parser) runValidatorList(ctx context.Context, m *LiteServerMetrics) error {
+	local := p.options.ValidatorList && m.Parsed("validator_index") && m.ValidatorIndex >= 0
+	if !local && !p.options.ValidatorSet {
+		return nil
+	}
+
+	output, err := p.run(ctx, validatorListCommand)
+	if err != nil {
+		m.setField("validator_list", false)
+		return fmt.Errorf("validator list: %w", err)
+	}
+
+	validators, err := parseValidatorList(output)
+	if err != nil {
+		m.setField("validator_list", false)
+		return fmt.Errorf("validator list: %w", err)
+	}
+
+	m.setField("validator_list", true)
+	if p.options.ValidatorSet {
+		m.setValidatorSet(validators, p.options.ValidatorSetLimit)
+	}
+	if !local {
+		return nil
+	}
+
+	index := int(m.ValidatorIndex)
+	if index < 0 || index >= len(validators) ||
+		(m.AdnlAddress != "" && validators[index].AdnlAddr != "" && validators[index].AdnlAddr != m.AdnlAddress) {
+		m.setField("validator_list", false)
+		return fmt.Errorf("validator list: local validator %d not found in %d validators", index, len(validators))
+	}
+
+	m.setLocalValidator(validators[index])
+
+	return nil
+}
+
+// setLocalValidator sets the efficiency and block metrics of the local validator.
+func (m *LiteServerMetrics) setLocalValidator(validator validatorListEntry) {
+	if validator.Efficiency != nil {
+		// mytoncore reports the efficiency in percent.
+		m.ValidatorEfficiencyRatio = *validator.Efficiency / 100
+		m.setField("validator_efficiency_ratio", true)
+	}
+	if validator.MasterBlocksCreated != nil && validator.WorkBlocksCreated != nil {
+		m.MasterBlocksCreated = *validator.MasterBlocksCreated
+		m.WorkBlocksCreated = *validator.WorkBlocksCreated
+		m.setField("blocks_created", true)
+	}
+	if validator.MasterBlocksExpected != nil && validator.WorkBlocksExpected != nil {
+		m.MasterBlocksExpected = *validator.MasterBlocksExpected
+		m.WorkBlocksExpected = *validator.WorkBlocksExpected
+		m.setField("blocks_expected", true)
+	}
+}
+
+// setValidatorSet sets the validators of the current set, keeping at most limit of them.
+func (m *LiteServerMetrics) setValidatorSet(validators []validatorListEntry, limit int) {
+	if limit > 0 && len(validators) > limit {
+		m.ValidatorSetDropped = float64(len(validators) - limit)
+		validators = validators[:limit]
+	}
+
+	m.Validators = make([]Validator, 0, len(validators))
+	for _, entry := range validators {
+		validator := Validator{
+			AdnlAddress:   entry.AdnlAddr,
+			PublicKey:     entry.Pubkey,
+			WalletAddress: entry.WalletAddr,
+			Online:        entry.Online,
+			Weight:        entry.Weight,
+			StakeTONs:     entry.Stake,
+		}
+		if entry.Efficiency != nil {
+			ratio := *entry.Efficiency / 100
+			validator.EfficiencyRatio = &ratio
+		}
+		m.Validators = append(m.Validators, validator)
+	}
+	m.setField("validators", true)
+}
+
+// run runs a mytonctrl console command on the source within the timeout.
+func (p *Parser) run(ctx context.Context, command string) (string, error) {
+	runner, ok := p.source.(CommandRunner)
+	if !ok {
+		return "", errors.New("source cannot run mytonctrl commands")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return runner.Run(ctx, command)
+}
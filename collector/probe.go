@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	probeSuccessDesc = prometheus.NewDesc(
+		"probe_success",
+		"Whether the probe of the target was successful",
+		nil, nil,
+	)
+	probeDurationDesc = prometheus.NewDesc(
+		"probe_duration_seconds",
+		"Duration of the probe of the target in seconds",
+		nil, nil,
+	)
+)
+
+// ProbeCollector exports the result of a single probe of a target, the way
+// blackbox_exporter does: probe_success, probe_duration_seconds and, when the
+// probe succeeded, the target's metrics.
+type ProbeCollector struct {
+	metrics  []MetricDef
+	result   *LiteServerMetrics
+	duration time.Duration
+}
+
+// Probe runs the parser once and returns a collector exporting its result.
+// The error is returned for logging only, the collector reports it as probe_success 0.
+func Probe(ctx context.Context, parser *Parser) (*ProbeCollector, error) {
+	start := time.Now()
+	result, err := parser.Parse(ctx)
+
+	return &ProbeCollector{
+		metrics:  Metrics,
+		result:   result,
+		duration: time.Since(start),
+	}, err
+}
+
+func (collector *ProbeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, mDef := range collector.metrics {
+		ch <- mDef.desc
+	}
+	ch <- probeSuccessDesc
+	ch <- probeDurationDesc
+}
+
+func (collector *ProbeCollector) Collect(ch chan<- prometheus.Metric) {
+	success := 0.0
+	if collector.result != nil {
+		success = 1
+		collectMetrics(ch, collector.metrics, collector.result, time.Now())
+	}
+
+	ch <- prometheus.MustNewConstMetric(probeSuccessDesc, prometheus.GaugeValue, success)
+	ch <- prometheus.MustNewConstMetric(probeDurationDesc, prometheus.GaugeValue, collector.duration.Seconds())
+}
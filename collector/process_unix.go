@@ -0,0 +1,24 @@
+//go:build !windows
+
+package collector
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// newShellCommand returns a shell command that runs in its own process group,
+// so the whole pipeline can be killed when it times out.
+func newShellCommand() *exec.Cmd {
+	command := exec.Command("/bin/sh", "-c")
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return command
+}
+
+// killProcessGroup kills every process in the command's process group.
+func killProcessGroup(command *exec.Cmd) {
+	if command.Process == nil {
+		return
+	}
+	_ = syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package collector
+
+import "os/exec"
+
+// newShellCommand returns a shell command to run the status pipeline.
+func newShellCommand() *exec.Cmd {
+	return exec.Command("cmd", "/C")
+}
+
+// killProcessGroup kills the command's process. Windows has no process groups
+// in the POSIX sense, so child processes are left to exit on their own.
+func killProcessGroup(command *exec.Cmd) {
+	if command.Process == nil {
+		return
+	}
+	_ = command.Process.Kill()
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// restartState holds the last seen uptimes and the restarts detected from them.
+// It is persisted in the state file so restarts keep counting across exporter restarts.
+type restartState struct {
+	MytoncoreUptimeSeconds float64 `json:"mytoncore_uptime_seconds"`
+	MytoncoreRestarts      float64 `json:"mytoncore_restarts"`
+	ValidatorUptimeSeconds float64 `json:"validator_uptime_seconds"`
+	ValidatorRestarts      float64 `json:"validator_restarts"`
+	// Seen reports whether the uptimes above were ever observed.
+	MytoncoreSeen bool `json:"mytoncore_seen"`
+	ValidatorSeen bool `json:"validator_seen"`
+}
+
+// observe counts a restart for every uptime that decreased since the last
+// observation and reports whether the state changed.
+func (s *restartState) observe(m *LiteServerMetrics) bool {
+	mytoncore := observeUptime(m, "mytoncore_uptime_seconds", m.MytoncoreUptimeSeconds,
+		&s.MytoncoreUptimeSeconds, &s.MytoncoreRestarts, &s.MytoncoreSeen)
+	validator := observeUptime(m, "local_validator_uptime_seconds", m.LocalValidatorUptimeSeconds,
+		&s.ValidatorUptimeSeconds, &s.ValidatorRestarts, &s.ValidatorSeen)
+	return mytoncore || validator
+}
+
+func observeUptime(m *LiteServerMetrics, field string, uptime float64, last, restarts *float64, seen *bool) bool {
+	if !m.Parsed(field) || (*seen && uptime == *last) {
+		return false
+	}
+	if *seen && uptime < *last {
+		*restarts++
+	}
+	*last, *seen = uptime, true
+	return true
+}
+
+// loadRestartState reads the state file at path, a missing file is an empty state.
+func loadRestartState(path string) (restartState, error) {
+	var state restartState
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("error parsing state file %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// save writes the state file at path, replacing it atomically.
+func (s *restartState) save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("error encoding state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps the size units printed by mytonctrl to bytes. mytonctrl
+// computes sizes in powers of 1000, GetDbSize divides by 10**9, and prints them
+// as Kb, Mb, Gb and Tb. Only the IEC units are powers of 1024.
+var sizeUnits = map[string]float64{
+	"b": 1, "byte": 1, "bytes": 1,
+	"k": 1e3, "kb": 1e3, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pib": 1 << 50,
+	"б": 1, "кб": 1e3, "мб": 1e6, "гб": 1e9, "тб": 1e12,
+}
+
+// sizeDefaultUnit is the unit of a size printed without one, mytonctrl prints
+// the database size in GB.
+const sizeDefaultUnit = "gb"
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(\p{L}*)$`)
+
+// parseSize parses a size printed by mytonctrl, such as "27.31 Gb" or
+// "512 Mb", and returns it in bytes. A bare number is a number of GB.
+func parseSize(value string) (float64, bool) {
+	match := sizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, false
+	}
+
+	amount, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	if match[2] == "" {
+		match[2] = sizeDefaultUnit
+	}
+	unit, ok := sizeUnits[match[2]]
+	if !ok {
+		return 0, false
+	}
+
+	return amount * unit, true
+}
+
+// parsePercent parses a percentage such as "82.0%" and returns it as a ratio.
+func parsePercent(value string) (float64, bool) {
+	percent, ok := parseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	return percent / 100, ok
+}
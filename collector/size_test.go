@@ -0,0 +1,34 @@
+package collector
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{value: "27.31 Gb", want: 27.31e9, wantOK: true},
+		{value: "512 Mb", want: 512e6, wantOK: true},
+		{value: "1.5 Tb", want: 1.5e12, wantOK: true},
+		{value: "100 Kb", want: 100e3, wantOK: true},
+		{value: "2GiB", want: 2 << 30, wantOK: true},
+		{value: " 3 GB ", want: 3e9, wantOK: true},
+		{value: "10 Гб", want: 10e9, wantOK: true},
+		{value: "4096 bytes", want: 4096, wantOK: true},
+		{value: "25.89", want: 25.89e9, wantOK: true},
+		{value: ""},
+		{value: "Gb"},
+		{value: "12 parsecs"},
+		{value: "1 Gb 2 Mb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseSize(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseSize(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/commander-cli/cmd"
+)
+
+// DefaultMytonctrlPath is the mytonctrl binary used when no path is configured.
+const DefaultMytonctrlPath = "mytonctrl"
+
+// Source provides the raw 'mytonctrl status' output to the Parser.
+type Source interface {
+	Fetch(ctx context.Context) (string, error)
+}
+
+// CommandRunner is implemented by sources that can run mytonctrl console
+// commands other than 'status', such as 'vl'.
+type CommandRunner interface {
+	Run(ctx context.Context, command string) (string, error)
+}
+
+// CommandSource runs a shell command line and returns its stdout.
+type CommandSource struct {
+	name    string
+	command string
+	// args is the command line mytonctrl console commands are piped into, it is
+	// empty for arbitrary shell commands that only print the status.
+	args []string
+	// unavailable reports whether a failed run means that the container, user
+	// or process to run mytonctrl in is missing, rather than mytonctrl failing.
+	unavailable func(stderr string) bool
+}
+
+// NewCommandSource returns a Source running the given shell command line.
+func NewCommandSource(command string) *CommandSource {
+	return &CommandSource{name: "command", command: command}
+}
+
+// NewMytonctrlSource returns a Source running 'mytonctrl status' on the local host.
+func NewMytonctrlSource(mytonctrlPath string) *CommandSource {
+	return &CommandSource{
+		name:    "mytonctrl",
+		command: statusCommand(mytonctrlPath),
+		args:    []string{mytonctrlPath},
+	}
+}
+
+// NewDockerSource returns a Source running 'mytonctrl status' inside a running container.
+func NewDockerSource(dockerPath, container, mytonctrlPath string) *CommandSource {
+	return &CommandSource{
+		name:    "docker",
+		command: statusCommand(dockerPath, "exec", "-i", container, mytonctrlPath),
+		args:    []string{dockerPath, "exec", "-i", container, mytonctrlPath},
+		unavailable: func(stderr string) bool {
+			return strings.Contains(stderr, "No such container") || strings.Contains(stderr, "is not running")
+		},
+	}
+}
+
+// NewSudoSource returns a Source running 'mytonctrl status' as another user.
+func NewSudoSource(sudoPath, user, mytonctrlPath string) *CommandSource {
+	return &CommandSource{
+		name:    "sudo",
+		command: statusCommand(sudoPath, "-n", "-u", user, mytonctrlPath),
+		args:    []string{sudoPath, "-n", "-u", user, mytonctrlPath},
+		unavailable: func(stderr string) bool {
+			return strings.Contains(stderr, "unknown user")
+		},
+	}
+}
+
+// NewNsenterSource returns a Source running 'mytonctrl status' in the namespaces of the process pid.
+func NewNsenterSource(nsenterPath string, pid int, mytonctrlPath string) *CommandSource {
+	return &CommandSource{
+		name:    "nsenter",
+		command: statusCommand(nsenterPath, "--target", strconv.Itoa(pid), "--all", mytonctrlPath),
+		args:    []string{nsenterPath, "--target", strconv.Itoa(pid), "--all", mytonctrlPath},
+		// Only nsenter's own errors about the target process mean it is missing,
+		// a missing mytonctrl in its namespaces is a configuration error.
+		unavailable: func(stderr string) bool {
+			return strings.Contains(stderr, "nsenter: cannot open /proc/")
+		},
+	}
+}
+
+// NewSSHSource returns a Source running 'mytonctrl status' on a remote host over SSH.
+// The host may include the user, e.g. "ton@10.0.0.1".
+func NewSSHSource(sshPath, host, mytonctrlPath string) *CommandSource {
+	return &CommandSource{
+		name:    "ssh",
+		command: statusCommand(sshPath, "-o", "BatchMode=yes", host, mytonctrlPath),
+		args:    []string{sshPath, "-o", "BatchMode=yes", host, mytonctrlPath},
+		unavailable: func(stderr string) bool {
+			return strings.Contains(stderr, "ssh: ") || strings.Contains(stderr, "Permission denied")
+		},
+	}
+}
+
+// Fetch runs the command until it exits or ctx is done.
+func (s *CommandSource) Fetch(ctx context.Context) (string, error) {
+	return s.execute(ctx, s.command)
+}
+
+// Run runs another mytonctrl console command the same way as 'status'.
+func (s *CommandSource) Run(ctx context.Context, command string) (string, error) {
+	if len(s.args) == 0 {
+		return "", fmt.Errorf("%s source: %w: cannot run mytonctrl command %q", s.name, ErrExec, command)
+	}
+
+	return s.execute(ctx, consoleCommand(command, s.args...))
+}
+
+func (s *CommandSource) execute(ctx context.Context, commandLine string) (string, error) {
+	baseCommand := newShellCommand()
+	command := cmd.NewCommand(commandLine,
+		cmd.WithCustomBaseCommand(baseCommand),
+		cmd.WithInheritedEnvironment(nil),
+		cmd.WithoutTimeout,
+	)
+
+	if err := command.ExecuteContext(ctx); err != nil {
+		if ctx.Err() == nil {
+			return "", fmt.Errorf("%s source: command %q: %w: %w", s.name, command.Command, ErrExec, err)
+		}
+		// commander-cli only kills the /bin/sh it started on cancellation. The shell
+		// runs in its own process group, so kill the group to stop the local
+		// processes of the pipeline as well, such as echo, mytonctrl, sudo, docker
+		// or ssh. Processes they started elsewhere, inside a container or on the
+		// remote host, are not in the group and are left to exit on their own.
+		// Windows has no process groups, there only the shell itself is killed.
+		killProcessGroup(baseCommand)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s source: command %q: %w", s.name, command.Command, ErrTimeout)
+		}
+		return "", fmt.Errorf("%s source: command %q: %w: %w", s.name, command.Command, ErrExec, ctx.Err())
+	}
+
+	if command.ExitCode() != 0 {
+		if s.unavailable != nil && s.unavailable(command.Stderr()) {
+			return "", fmt.Errorf("%s source: %w: %s", s.name, ErrUnavailable, strings.TrimSpace(command.Stderr()))
+		}
+		return "", fmt.Errorf("%s source: command %q failed with %w %d: %s",
+			s.name, command.Command, ErrExitCode, command.ExitCode(), command.Combined())
+	}
+
+	return command.Stdout(), nil
+}
+
+var shellSafe = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// statusCommand builds a shell command line piping 'status' into the given command.
+func statusCommand(args ...string) string {
+	return consoleCommand("status", args...)
+}
+
+// consoleCommand builds a shell command line piping the mytonctrl console
+// command input into the given command.
+func consoleCommand(input string, args ...string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	return "echo " + singleQuote(input) + " | " + strings.Join(quoted, " ")
+}
+
+func shellQuote(arg string) string {
+	if !shellSafe.MatchString(arg) {
+		return singleQuote(arg)
+	}
+	return arg
+}
+
+func singleQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// FileSource reads the output from a file, re-reading it on every fetch.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource returns a Source reading the file at path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Fetch returns the current file contents.
+func (s *FileSource) Fetch(_ context.Context) (string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrExec, err)
+	}
+
+	return string(data), nil
+}
+
+// ReaderSource reads the output from a reader such as stdin. The reader is
+// consumed on the first fetch and its contents are returned on every fetch.
+type ReaderSource struct {
+	reader io.Reader
+	once   sync.Once
+	output string
+	err    error
+}
+
+// NewReaderSource returns a Source reading from r.
+func NewReaderSource(r io.Reader) *ReaderSource {
+	return &ReaderSource{reader: r}
+}
+
+// Fetch returns the reader contents.
+func (s *ReaderSource) Fetch(_ context.Context) (string, error) {
+	s.once.Do(func() {
+		data, err := io.ReadAll(s.reader)
+		if err != nil {
+			s.err = fmt.Errorf("%w: %w", ErrExec, err)
+			return
+		}
+		s.output = string(data)
+	})
+
+	return s.output, s.err
+}
@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MytoncoreDBSource reads the JSON database mytoncore keeps its state in, by
+// default ~/.local/share/mytoncore/mytoncore.db of the user running mytoncore.
+// The database is converted to a JSON status object and only re-read when its
+// modification time or size changes.
+type MytoncoreDBSource struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	output  string
+}
+
+// NewMytoncoreDBSource returns a Source reading the mytoncore database at path.
+func NewMytoncoreDBSource(path string) *MytoncoreDBSource {
+	return &MytoncoreDBSource{path: path}
+}
+
+// mytoncoreDB holds the parts of the mytoncore database the exporter reads.
+type mytoncoreDB struct {
+	AdnlAddr string `json:"adnlAddr"`
+	// SaveElections maps election ids to the election entries keyed by ADNL address.
+	SaveElections map[string]map[string]mytoncoreElectionEntry `json:"saveElections"`
+}
+
+type mytoncoreElectionEntry struct {
+	AdnlAddr   string  `json:"adnlAddr"`
+	Stake      float64 `json:"stake"`
+	WalletAddr string  `json:"walletAddr"`
+}
+
+// Fetch returns the database as a JSON status object, reusing the previous
+// result while the file is unchanged.
+func (s *MytoncoreDBSource) Fetch(_ context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("mytoncore db source: %w: %w", ErrExec, err)
+	}
+	if s.output != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.output, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("mytoncore db source: %w: %w", ErrExec, err)
+	}
+
+	var db mytoncoreDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return "", fmt.Errorf("mytoncore db source: %s: %w: %w", s.path, ErrParse, err)
+	}
+
+	output, err := json.Marshal(db.status())
+	if err != nil {
+		return "", fmt.Errorf("mytoncore db source: %w: %w", ErrParse, err)
+	}
+
+	s.modTime, s.size, s.output = info.ModTime(), info.Size(), string(output)
+
+	return s.output, nil
+}
+
+// status returns the status object fields known from the database, keyed by
+// their LiteServerMetrics JSON names. Fields the database lacks are left out.
+// The saved offers and complaints are mytoncore's history of every round, not
+// the current counts of the status, so they are not read.
+func (db *mytoncoreDB) status() map[string]any {
+	status := make(map[string]any)
+	if db.AdnlAddr != "" {
+		status["adnl_address"] = db.AdnlAddr
+	}
+
+	if db.AdnlAddr == "" || db.SaveElections == nil {
+		return status
+	}
+
+	stakes := []PastStake{}
+	latest, wallet := "", ""
+	for electionID, entries := range db.SaveElections {
+		entry, ok := entries[db.AdnlAddr]
+		if !ok {
+			continue
+		}
+		stakes = append(stakes, PastStake{ElectionID: electionID, StakeTONs: entry.Stake})
+		if latest == "" || electionIDLess(latest, electionID) {
+			latest, wallet = electionID, entry.WalletAddr
+		}
+	}
+	sort.Slice(stakes, func(i, j int) bool {
+		return electionIDLess(stakes[i].ElectionID, stakes[j].ElectionID)
+	})
+
+	status["past_stakes"] = stakes
+	if id, err := strconv.ParseFloat(latest, 64); err == nil {
+		status["latest_participated_election_id"] = id
+	}
+	if wallet != "" {
+		status["wallet_address"] = wallet
+	}
+
+	return status
+}
+
+// electionIDLess orders election ids, which are the unix times the elections started.
+func electionIDLess(a, b string) bool {
+	x, errA := strconv.ParseInt(a, 10, 64)
+	y, errB := strconv.ParseInt(b, 10, 64)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x < y
+}
@@ -0,0 +1,318 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCommandSource_Fetch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands are POSIX specific")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		timeout time.Duration
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "stdout",
+			command: "echo 'Network name: testnet'",
+			want:    "Network name: testnet\n",
+		},
+		{
+			name:    "non-zero exit code",
+			command: "echo failed >&2; exit 3",
+			wantErr: ErrExitCode,
+		},
+		{
+			name:    "timeout",
+			command: "sleep 10 | cat",
+			timeout: 100 * time.Millisecond,
+			wantErr: ErrTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			got, err := NewCommandSource(tt.command).Fetch(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CommandSource.Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CommandSource.Fetch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerSource_Fetch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands are POSIX specific")
+	}
+
+	// The fake docker binary mimics 'docker exec' for a missing container.
+	docker := filepath.Join(t.TempDir(), "docker")
+	script := "#!/bin/sh\necho \"Error response from daemon: No such container: $3\" >&2\nexit 1\n"
+	if err := os.WriteFile(docker, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewDockerSource(docker, "ton", DefaultMytonctrlPath).Fetch(context.Background())
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("DockerSource.Fetch() error = %v, want %v", err, ErrUnavailable)
+	}
+}
+
+func TestCommandSource_Unavailable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell commands are POSIX specific")
+	}
+
+	tests := []struct {
+		name      string
+		newSource func(path string) *CommandSource
+		stderr    string
+		exitCode  int
+		wantErr   error
+	}{
+		{
+			name:      "sudo unknown user",
+			newSource: func(path string) *CommandSource { return NewSudoSource(path, "ton", DefaultMytonctrlPath) },
+			stderr:    "sudo: unknown user ton",
+			exitCode:  1,
+			wantErr:   ErrUnavailable,
+		},
+		{
+			name:      "nsenter missing process",
+			newSource: func(path string) *CommandSource { return NewNsenterSource(path, 424242, DefaultMytonctrlPath) },
+			stderr:    "nsenter: cannot open /proc/424242/ns/cgroup: No such file or directory",
+			exitCode:  1,
+			wantErr:   ErrUnavailable,
+		},
+		{
+			name:      "nsenter missing mytonctrl",
+			newSource: func(path string) *CommandSource { return NewNsenterSource(path, 1, "/opt/mytonctrl") },
+			stderr:    "nsenter: failed to execute /opt/mytonctrl: No such file or directory",
+			exitCode:  127,
+			wantErr:   ErrExitCode,
+		},
+		{
+			name:      "ssh unreachable host",
+			newSource: func(path string) *CommandSource { return NewSSHSource(path, "ton@node1", DefaultMytonctrlPath) },
+			stderr:    "ssh: Could not resolve hostname node1: Name or service not known",
+			exitCode:  255,
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake binary fails the way the real one does.
+			path := filepath.Join(t.TempDir(), "fake")
+			script := fmt.Sprintf("#!/bin/sh\necho '%s' >&2\nexit %d\n", tt.stderr, tt.exitCode)
+			if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := tt.newSource(path).Fetch(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CommandSource.Fetch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != ErrUnavailable && errors.Is(err, ErrUnavailable) {
+				t.Errorf("CommandSource.Fetch() error = %v, want no %v", err, ErrUnavailable)
+			}
+		})
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"mytonctrl"}, want: "echo 'status' | mytonctrl"},
+		{args: []string{"sudo", "-n", "-u", "ton", "/usr/bin/mytonctrl"}, want: "echo 'status' | sudo -n -u ton /usr/bin/mytonctrl"},
+		{args: []string{"docker", "exec", "-i", "my node", "mytonctrl"}, want: "echo 'status' | docker exec -i 'my node' mytonctrl"},
+		{args: []string{"it's"}, want: `echo 'status' | 'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := statusCommand(tt.args...); got != tt.want {
+			t.Errorf("statusCommand(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFileSource_Fetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.txt")
+	if err := os.WriteFile(path, []byte("Network name: testnet\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewFileSource(path).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("FileSource.Fetch() error = %v", err)
+	}
+	if got != "Network name: testnet\n" {
+		t.Errorf("FileSource.Fetch() = %q", got)
+	}
+
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing.txt")).Fetch(context.Background()); !errors.Is(err, ErrExec) {
+		t.Errorf("FileSource.Fetch() error = %v, want %v", err, ErrExec)
+	}
+}
+
+func TestReaderSource_Fetch(t *testing.T) {
+	source := NewReaderSource(strings.NewReader("Network name: testnet\n"))
+	for i := 0; i < 2; i++ {
+		got, err := source.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("ReaderSource.Fetch() error = %v", err)
+		}
+		if got != "Network name: testnet\n" {
+			t.Errorf("ReaderSource.Fetch() = %q", got)
+		}
+	}
+}
+
+func TestMytoncoreDBSource_Fetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mytoncore.db")
+	db := `{
+  "adnlAddr": "AA11",
+  "saveElections": {
+    "1700065536": {"AA11": {"adnlAddr": "AA11", "stake": 350000.5, "walletAddr": "Ef-old"}},
+    "1700131072": {"AA11": {"adnlAddr": "AA11", "stake": 360000.0, "walletAddr": "Ef-new"}},
+    "1700196608": {"BB22": {"adnlAddr": "BB22", "stake": 100000.0, "walletAddr": "Ef-other"}}
+  },
+  "saveOffers": {"hash1": {}, "hash2": {}},
+  "saveComplaints": {"1700065536": {"c1": {}}, "1700131072": {"c2": {}, "c3": {}}}
+}`
+	if err := os.WriteFile(path, []byte(db), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewMytoncoreDBSource(path)
+	output, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("MytoncoreDBSource.Fetch() error = %v", err)
+	}
+	got, err := (&Parser{}).ParseOutput(output)
+	if err != nil {
+		t.Fatalf("ParseOutput() error = %v", err)
+	}
+
+	// The saved offers and complaints are history and leave the counts unset.
+	want := LiteServerMetrics{
+		AdnlAddress:   "AA11",
+		WalletAddress: "Ef-new",
+		PastStakes: []PastStake{
+			{ElectionID: "1700065536", StakeTONs: 350000.5},
+			{ElectionID: "1700131072", StakeTONs: 360000},
+		},
+		LatestParticipatedElectionID: 1700131072,
+		ParsedFields: map[string]bool{
+			"adnl_address":                    true,
+			"wallet_address":                  true,
+			"past_stakes":                     true,
+			"latest_participated_election_id": true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseOutput() mismatch (-want +got):\n%s", diff)
+	}
+
+	// An unchanged file is not read again.
+	if err := os.WriteFile(path, []byte(`{"adnlAddr": "CC33"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, int64(len(db))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if cached, err := source.Fetch(context.Background()); err != nil || cached != output {
+		t.Errorf("MytoncoreDBSource.Fetch() = %q, %v, want the cached output", cached, err)
+	}
+
+	// A modified file is read again.
+	if err := os.WriteFile(path, []byte(`{"adnlAddr": "CC33"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output, err = source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("MytoncoreDBSource.Fetch() error = %v", err)
+	}
+	if output != `{"adnl_address":"CC33"}` {
+		t.Errorf("MytoncoreDBSource.Fetch() = %q", output)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Fetch(context.Background()); !errors.Is(err, ErrParse) {
+		t.Errorf("MytoncoreDBSource.Fetch() error = %v, want %v", err, ErrParse)
+	}
+}
+
+func TestParser_PastStakesLimit(t *testing.T) {
+	output := `{"past_stakes": [
+  {"election_id": "1700196608", "stake_tons": 3},
+  {"election_id": "1700065536", "stake_tons": 1},
+  {"election_id": "1700131072", "stake_tons": 2}
+]}`
+
+	tests := []struct {
+		limit int
+		want  []PastStake
+	}{
+		{
+			limit: 0,
+			want: []PastStake{
+				{ElectionID: "1700196608", StakeTONs: 3},
+				{ElectionID: "1700065536", StakeTONs: 1},
+				{ElectionID: "1700131072", StakeTONs: 2},
+			},
+		},
+		{
+			limit: 2,
+			want: []PastStake{
+				{ElectionID: "1700131072", StakeTONs: 2},
+				{ElectionID: "1700196608", StakeTONs: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NewParser(staticSource{output: output}, 0, ParserOptions{PastStakesLimit: tt.limit}).Parse(context.Background())
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if diff := cmp.Diff(tt.want, got.PastStakes); diff != "" {
+			t.Errorf("Parse() with limit %d mismatch (-want +got):\n%s", tt.limit, diff)
+		}
+	}
+}
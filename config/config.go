@@ -0,0 +1,367 @@
+// Package config loads the exporter configuration file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source types supported by SourceConfig.Type.
+const (
+	SourceMytonctrl = "mytonctrl"
+	SourceDocker    = "docker"
+	SourceSudo      = "sudo"
+	SourceNsenter   = "nsenter"
+	SourceSSH       = "ssh"
+	SourceCommand   = "command"
+	SourceFile      = "file"
+	SourceStdin     = "stdin"
+	// SourceMytoncoreDB reads mytoncore's JSON database instead of running mytonctrl.
+	SourceMytoncoreDB = "mytoncore_db"
+)
+
+// Config is the exporter configuration file.
+type Config struct {
+	// ListenAddress is the address the HTTP server listens on, e.g. ":9100".
+	ListenAddress string `yaml:"listen_address"`
+	// Timeout limits every mytonctrl command of a scrape on its own, 0 disables it.
+	Timeout time.Duration `yaml:"timeout"`
+	// PollInterval enables background polling when positive.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// StateFile persists the detected restarts across exporter restarts when set.
+	StateFile string `yaml:"state_file,omitempty"`
+	// Source is where the status output of the local node is read from.
+	Source SourceConfig `yaml:"source"`
+	// Collect enables the optional mytonctrl commands run after 'status'.
+	Collect CollectConfig `yaml:"collect"`
+	// Metrics selects the exported metrics.
+	Metrics MetricsConfig `yaml:"metrics"`
+	// ConstLabels are added to every exported metric of the exporter.
+	ConstLabels map[string]string `yaml:"const_labels,omitempty"`
+	// Targets are the nodes served by the /probe endpoint.
+	Targets []Target `yaml:"targets,omitempty"`
+}
+
+// CollectConfig enables the optional mytonctrl commands run after 'status'.
+// They are only supported by sources that run mytonctrl.
+type CollectConfig struct {
+	// Structured reads the validator, offer and complaint counts from 'vl', 'ol' and 'cl' JSON.
+	Structured bool `yaml:"structured"`
+	// ValidatorList reads the efficiency and created blocks of the local validator from 'vl'.
+	ValidatorList bool `yaml:"validator_list"`
+	// ValidatorSet reads every validator of the current set from 'vl'.
+	ValidatorSet bool `yaml:"validator_set"`
+	// ValidatorSetLimit caps the number of validators exported by ValidatorSet.
+	ValidatorSetLimit int `yaml:"validator_set_limit"`
+	// PastStakesLimit caps the number of elections exported by past_stake_tons.
+	PastStakesLimit int `yaml:"past_stakes_limit"`
+	// OffersComplaints reads the open offers and the complaints from 'ol' and 'cl'.
+	OffersComplaints bool `yaml:"offers_complaints"`
+	// ElectionEntries reads the stake of the local validator in the current election from 'el'.
+	ElectionEntries bool `yaml:"election_entries"`
+	// Accounts are additional wallets and contracts whose balance and state are read from 'vas'.
+	Accounts []AccountConfig `yaml:"accounts,omitempty"`
+	// AccountsInterval is the minimum time between two queries of the same account.
+	AccountsInterval time.Duration `yaml:"accounts_interval"`
+	// Pools reads the nominator pools of the node from 'pools_list' and 'get_pool_data'.
+	Pools bool `yaml:"pools"`
+}
+
+// AccountConfig is a wallet or contract watched through 'vas'.
+type AccountConfig struct {
+	Address string `yaml:"address"`
+	// Alias names the account in the alias label of its balance.
+	Alias string `yaml:"alias,omitempty"`
+}
+
+// MetricsConfig filters the exported metrics by their full name. Patterns are
+// anchored regular expressions, a metric is exported when it matches any
+// include pattern (or there are none) and no exclude pattern.
+type MetricsConfig struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+	// LegacyDatabaseSizeGB keeps exporting the deprecated database size gauge in GB.
+	LegacyDatabaseSizeGB bool `yaml:"legacy_database_size_gb,omitempty"`
+}
+
+// legacyDatabaseSizeGB is the deprecated database size gauge, only exported
+// with MetricsConfig.LegacyDatabaseSizeGB.
+const legacyDatabaseSizeGB = "ton_liteserver_exporter_local_validator_database_size_gb"
+
+// MetricFilter is the compiled form of MetricsConfig.
+type MetricFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Target is a node probed through /probe?target=<name>.
+type Target struct {
+	Name    string        `yaml:"name"`
+	Source  SourceConfig  `yaml:"source"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// SourceConfig describes where the 'mytonctrl status' output is read from.
+type SourceConfig struct {
+	Type            string `yaml:"type"`
+	Command         string `yaml:"command,omitempty"`
+	File            string `yaml:"file,omitempty"`
+	MytoncoreDB     string `yaml:"mytoncore_db,omitempty"`
+	DockerContainer string `yaml:"docker_container,omitempty"`
+	SudoUser        string `yaml:"sudo_user,omitempty"`
+	NsenterPID      int    `yaml:"nsenter_pid,omitempty"`
+	SSHHost         string `yaml:"ssh_host,omitempty"`
+	MytonctrlPath   string `yaml:"mytonctrl_path,omitempty"`
+	DockerPath      string `yaml:"docker_path,omitempty"`
+	SudoPath        string `yaml:"sudo_path,omitempty"`
+	NsenterPath     string `yaml:"nsenter_path,omitempty"`
+	SSHPath         string `yaml:"ssh_path,omitempty"`
+}
+
+var (
+	labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// accountAddress matches the raw and user-friendly forms of TON addresses.
+	accountAddress = regexp.MustCompile(`^(?:-?\d+:[0-9a-fA-F]{64}|[A-Za-z0-9_+/=-]{48})$`)
+)
+
+// Load reads the configuration file at path. The result is validated by the
+// caller once command line overrides are applied.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if err := LoadInto(path, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadInto reads the configuration file at path into cfg. Values missing from
+// the file keep their value in cfg, so defaults can be set beforehand.
+func LoadInto(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error parsing config %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Validate checks that the configuration is complete.
+func (c *Config) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if c.PollInterval < 0 {
+		return errors.New("poll_interval must not be negative")
+	}
+	if c.Collect.ValidatorSetLimit < 0 {
+		return errors.New("collect.validator_set_limit must not be negative")
+	}
+	if c.Collect.PastStakesLimit < 0 {
+		return errors.New("collect.past_stakes_limit must not be negative")
+	}
+	if c.Collect.AccountsInterval < 0 {
+		return errors.New("collect.accounts_interval must not be negative")
+	}
+	addresses := make(map[string]struct{}, len(c.Collect.Accounts))
+	for i, account := range c.Collect.Accounts {
+		if !accountAddress.MatchString(account.Address) {
+			return fmt.Errorf("collect.accounts[%d]: invalid address %q", i, account.Address)
+		}
+		if _, ok := addresses[account.Address]; ok {
+			return fmt.Errorf("collect.accounts[%d]: duplicate address %q", i, account.Address)
+		}
+		addresses[account.Address] = struct{}{}
+	}
+	if err := c.Source.Validate(); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if err := c.Collect.validateSource(c.Source); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if _, err := c.Metrics.Filter(); err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	for name := range c.ConstLabels {
+		if !labelName.MatchString(name) {
+			return fmt.Errorf("const_labels: invalid label name %q", name)
+		}
+	}
+
+	names := make(map[string]struct{}, len(c.Targets))
+	for i, target := range c.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("targets[%d]: name is required", i)
+		}
+		if _, ok := names[target.Name]; ok {
+			return fmt.Errorf("targets[%d]: duplicate target %q", i, target.Name)
+		}
+		names[target.Name] = struct{}{}
+
+		if target.Source.Type == SourceStdin {
+			return fmt.Errorf("target %q: the stdin source cannot be probed", target.Name)
+		}
+		if err := target.Source.Validate(); err != nil {
+			return fmt.Errorf("target %q: %w", target.Name, err)
+		}
+		if err := c.Collect.validateSource(target.Source); err != nil {
+			return fmt.Errorf("target %q: %w", target.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Target returns the target with the given name.
+func (c *Config) Target(name string) (Target, bool) {
+	for _, target := range c.Targets {
+		if target.Name == name {
+			return target, true
+		}
+	}
+
+	return Target{}, false
+}
+
+// Filter compiles the metric name patterns.
+func (m MetricsConfig) Filter() (*MetricFilter, error) {
+	include, err := compilePatterns(m.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	exclude, err := compilePatterns(m.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+	if !m.LegacyDatabaseSizeGB {
+		exclude = append(exclude, regexp.MustCompile("^"+regexp.QuoteMeta(legacyDatabaseSizeGB)+"$"))
+	}
+
+	return &MetricFilter{include: include, exclude: exclude}, nil
+}
+
+// Match reports whether the metric with the given full name is exported.
+func (f *MetricFilter) Match(name string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		res = append(res, re)
+	}
+
+	return res, nil
+}
+
+// validateSource checks that the source can run the enabled mytonctrl commands.
+func (c CollectConfig) validateSource(source SourceConfig) error {
+	if source.RunsMytonctrl() {
+		return nil
+	}
+	if c.Structured {
+		return fmt.Errorf("collect.structured is not supported by the %s source", source.Type)
+	}
+	if c.ValidatorList {
+		return fmt.Errorf("collect.validator_list is not supported by the %s source", source.Type)
+	}
+	if c.ValidatorSet {
+		return fmt.Errorf("collect.validator_set is not supported by the %s source", source.Type)
+	}
+	if c.OffersComplaints {
+		return fmt.Errorf("collect.offers_complaints is not supported by the %s source", source.Type)
+	}
+	if c.ElectionEntries {
+		return fmt.Errorf("collect.election_entries is not supported by the %s source", source.Type)
+	}
+	if len(c.Accounts) > 0 {
+		return fmt.Errorf("collect.accounts is not supported by the %s source", source.Type)
+	}
+	if c.Pools {
+		return fmt.Errorf("collect.pools is not supported by the %s source", source.Type)
+	}
+
+	return nil
+}
+
+// RunsMytonctrl reports whether the source runs mytonctrl itself, so it can
+// run console commands other than 'status'.
+func (s *SourceConfig) RunsMytonctrl() bool {
+	switch s.Type {
+	case "", SourceMytonctrl, SourceDocker, SourceSudo, SourceNsenter, SourceSSH:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks that the options required by the source type are set.
+func (s *SourceConfig) Validate() error {
+	switch s.Type {
+	case "", SourceMytonctrl, SourceStdin:
+		return nil
+	case SourceDocker:
+		if s.DockerContainer == "" {
+			return errors.New("docker_container is required for the docker source")
+		}
+	case SourceSudo:
+		if s.SudoUser == "" {
+			return errors.New("sudo_user is required for the sudo source")
+		}
+	case SourceNsenter:
+		if s.NsenterPID <= 0 {
+			return errors.New("nsenter_pid is required for the nsenter source")
+		}
+	case SourceSSH:
+		if s.SSHHost == "" {
+			return errors.New("ssh_host is required for the ssh source")
+		}
+	case SourceCommand:
+		if s.Command == "" {
+			return errors.New("command is required for the command source")
+		}
+	case SourceFile:
+		if s.File == "" {
+			return errors.New("file is required for the file source")
+		}
+	case SourceMytoncoreDB:
+		if s.MytoncoreDB == "" {
+			return errors.New("mytoncore_db is required for the mytoncore_db source")
+		}
+	default:
+		return fmt.Errorf("unknown source type %q", s.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMetricFilter_Match(t *testing.T) {
+	filter, err := MetricsConfig{
+		Include: []string{"ton_liteserver_exporter_.*"},
+		Exclude: []string{".*_address"},
+	}.Filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"ton_liteserver_exporter_up":                               true,
+		"ton_liteserver_exporter_elector_address":                  false,
+		"go_goroutines":                                            false,
+		"prefix_ton_liteserver_exporter_all_validators":            false,
+		"ton_liteserver_exporter_local_validator_database_size_gb": false,
+	}
+	for name, want := range tests {
+		if got := filter.Match(name); got != want {
+			t.Errorf("MetricFilter.Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMetricFilter_MatchLegacy(t *testing.T) {
+	filter, err := MetricsConfig{LegacyDatabaseSizeGB: true}.Filter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !filter.Match(legacyDatabaseSizeGB) {
+		t.Errorf("MetricFilter.Match(%q) = false, want true", legacyDatabaseSizeGB)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "targets",
+			input: `
+targets:
+  - name: validator-1
+    timeout: 20s
+    source:
+      type: ssh
+      ssh_host: ton@10.0.0.1
+  - name: liteserver-1
+    source:
+      type: docker
+      docker_container: mytonctrl
+      mytonctrl_path: /usr/bin/mytonctrl
+`,
+			want: &Config{
+				Targets: []Target{
+					{
+						Name:    "validator-1",
+						Timeout: 20 * time.Second,
+						Source:  SourceConfig{Type: SourceSSH, SSHHost: "ton@10.0.0.1"},
+					},
+					{
+						Name:   "liteserver-1",
+						Source: SourceConfig{Type: SourceDocker, DockerContainer: "mytonctrl", MytonctrlPath: "/usr/bin/mytonctrl"},
+					},
+				},
+			},
+		},
+		{
+			name: "full config",
+			input: `
+listen_address: 127.0.0.1:9200
+timeout: 15s
+poll_interval: 1m
+source:
+  type: sudo
+  sudo_user: ton
+collect:
+  validator_list: true
+metrics:
+  include: [ton_liteserver_exporter_.*]
+  exclude: [.*_address]
+const_labels:
+  network: mainnet
+`,
+			want: &Config{
+				ListenAddress: "127.0.0.1:9200",
+				Timeout:       15 * time.Second,
+				PollInterval:  time.Minute,
+				Source:        SourceConfig{Type: SourceSudo, SudoUser: "ton"},
+				Collect:       CollectConfig{ValidatorList: true},
+				Metrics: MetricsConfig{
+					Include: []string{"ton_liteserver_exporter_.*"},
+					Exclude: []string{".*_address"},
+				},
+				ConstLabels: map[string]string{"network": "mainnet"},
+			},
+		},
+		{
+			name:    "unknown field",
+			input:   "listen: :9100\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid metric pattern",
+			input:   "metrics:\n  exclude: ['(']\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid label name",
+			input:   "const_labels:\n  0network: mainnet\n",
+			wantErr: true,
+		},
+		{
+			name: "duplicate target",
+			input: `
+targets:
+  - name: validator-1
+  - name: validator-1
+`,
+			wantErr: true,
+		},
+		{
+			name: "missing source option",
+			input: `
+targets:
+  - name: validator-1
+    source:
+      type: docker
+`,
+			wantErr: true,
+		},
+		{
+			name: "validator list with file source",
+			input: `
+collect:
+  validator_list: true
+source:
+  type: file
+  file: status.txt
+`,
+			wantErr: true,
+		},
+		{
+			name: "structured with stdin source",
+			input: `
+collect:
+  structured: true
+source:
+  type: stdin
+`,
+			wantErr: true,
+		},
+		{
+			name: "accounts",
+			input: `
+collect:
+  accounts:
+    - address: EQCkR1cGmnsE45N4K0otPl5EnxnRakmGqeJUNua5fkWhaLes
+      alias: pool
+    - address: -1:3333333333333333333333333333333333333333333333333333333333333333
+  accounts_interval: 10m
+`,
+			want: &Config{
+				Collect: CollectConfig{
+					Accounts: []AccountConfig{
+						{Address: "EQCkR1cGmnsE45N4K0otPl5EnxnRakmGqeJUNua5fkWhaLes", Alias: "pool"},
+						{Address: "-1:3333333333333333333333333333333333333333333333333333333333333333"},
+					},
+					AccountsInterval: 10 * time.Minute,
+				},
+			},
+		},
+		{
+			name:    "invalid account address",
+			input:   "collect:\n  accounts:\n    - address: \"EQ'; rm -rf /\"\n",
+			wantErr: true,
+		},
+		{
+			name: "duplicate account",
+			input: `
+collect:
+  accounts:
+    - address: EQCkR1cGmnsE45N4K0otPl5EnxnRakmGqeJUNua5fkWhaLes
+    - address: EQCkR1cGmnsE45N4K0otPl5EnxnRakmGqeJUNua5fkWhaLes
+`,
+			wantErr: true,
+		},
+		{
+			name: "pools with mytoncore db source",
+			input: `
+collect:
+  pools: true
+source:
+  type: mytoncore_db
+  mytoncore_db: /var/ton/mytoncore.db
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown source",
+			input: `
+targets:
+  - name: validator-1
+    source:
+      type: telnet
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yml")
+			if err := os.WriteFile(path, []byte(tt.input), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Load(path)
+			if err == nil {
+				err = got.Validate()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}